@@ -0,0 +1,1198 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/doctor"
+	"flutter_takeoff/pkg/installer"
+	"flutter_takeoff/pkg/jdk"
+	"flutter_takeoff/pkg/project"
+	"flutter_takeoff/pkg/releases"
+	"flutter_takeoff/pkg/sdks"
+	"flutter_takeoff/pkg/ui"
+)
+
+// Exit codes of the non-interactive subcommands
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command is a non-interactive subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"check", "Check that the prerequisites are installed", checkCommand},
+		{"install", "Download and install the Flutter SDK", installCommand},
+		{"flutter", "Show the Flutter channel and version, and switch them", flutterCommand},
+		{"sdks", "Install Flutter versions side by side and choose the current one", sdksCommand},
+		{"sync", "Install the toolchain a project's .flutter-takeoff.json pins", syncCommand},
+		{"android-sdk", "Install the Android command-line tools and SDK packages", androidSDKCommand},
+		{"emulator", "List system images and AVDs, and create AVDs", emulatorCommand},
+		{"licenses", "Review and accept the Android SDK licences", licensesCommand},
+		{"doctor", "Run flutter doctor", doctorCommand},
+		{"changes", "Show what changed between the last two doctor runs", changesCommand},
+		{"jdk", "List installed JDKs and choose the one Flutter uses", jdkCommand},
+		{"version", "Show version and build information", versionCommand},
+	}
+}
+
+// runCLI dispatches args to a subcommand and returns the exit code
+func runCLI(args []string) int {
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		return exitOK
+	case "-v", "-version", "--version":
+		name = "version"
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	return exitUsage
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: flutter-takeoff [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the interactive menu is started.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'flutter-takeoff <command> -h' for the flags of a command.")
+}
+
+// newFlagSet creates a flag set whose errors are returned to the caller
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: flutter-takeoff %s %s\n\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args, returning the exit code to use when the
+// command should stop (help requested or bad flags)
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected argument %q\n", fs.Arg(0))
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// newCLIInstaller creates the installer for the running platform
+func newCLIInstaller() (installer.Installer, *installer.InstallConfig, error) {
+	config := &installer.InstallConfig{
+		Platform: installer.DetectPlatform(),
+		Target:   installer.TargetAndroid,
+	}
+	inst, err := installer.New(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+	}
+	return inst, config, err
+}
+
+func checkCommand(args []string) int {
+	fs := newFlagSet("check", "[--output text|json]")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	report := installer.NewDependencyReport(config.Platform, inst.CheckDependencies(ctx))
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "dependency check cancelled")
+		return exitFailure
+	}
+	if *output == "json" {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	} else {
+		printDependencyReport(report.Dependencies)
+	}
+
+	if !report.Ready {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func installCommand(args []string) int {
+	fs := newFlagSet("install", "[--path DIR] [--version VERSION] [--channel CHANNEL] [--yes]")
+	path := fs.String("path", "", "installation directory (default: the platform default)")
+	flutterVersion := fs.String("version", installer.DefaultFlutterVersion, `Flutter version: "latest", a release line such as "3.22.x", or an exact version`)
+	channel := fs.String("channel", installer.DefaultChannel, "release channel: "+strings.Join(releases.Channels, ", "))
+	storageURL := fs.String("storage-url", "", "download mirror (default: $FLUTTER_STORAGE_BASE_URL, then Google storage)")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	fs.BoolVar(yes, "y", false, "shorthand for --yes")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+
+	config.FlutterPath = *path
+	if config.FlutterPath == "" {
+		config.FlutterPath = inst.GetDefaultFlutterPath()
+	}
+	config.FlutterVersion = *flutterVersion
+	config.Channel = *channel
+	config.StorageBaseURL = *storageURL
+
+	if !*yes {
+		question := fmt.Sprintf("Install Flutter %s (%s) into %s?", config.FlutterVersion, config.Channel, config.FlutterPath)
+		if !askYesNo(question) {
+			fmt.Println(ui.SubtleStyle.Render("\nInstallation cancelled.\n"))
+			return exitFailure
+		}
+	}
+
+	if err := installFlutter(context.Background(), inst, config, newProgressPrinter()); err != nil {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func flutterCommand(args []string) int {
+	fs := newFlagSet("flutter", "[--output text|json] [--path DIR] [--list [--channel CHANNEL] | --switch CHANNEL | --checkout VERSION [--channel CHANNEL]] [--yes]")
+	output := fs.String("output", "text", "output format of the version and --list: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	path := fs.String("path", "", "Flutter SDK directory (default: the flutter on PATH)")
+	list := fs.Bool("list", false, "list the versions released on --channel")
+	channel := fs.String("channel", installer.DefaultChannel, "with --list or --checkout, the release channel: "+strings.Join(releases.Channels, ", "))
+	switchTo := fs.String("switch", "", "switch to this channel with flutter channel, then run flutter upgrade")
+	checkout := fs.String("checkout", "", `check out this version with git: "latest", a release line such as "3.22.x", or an exact version`)
+	storageURL := fs.String("storage-url", "", "releases mirror (default: $FLUTTER_STORAGE_BASE_URL, then Google storage)")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	fs.BoolVar(yes, "y", false, "shorthand for --yes")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	actions := 0
+	for _, set := range []bool{*list, *switchTo != "", *checkout != ""} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		fmt.Fprintln(os.Stderr, "--list, --switch and --checkout cannot be combined")
+		return exitUsage
+	}
+	if *output == "json" && (*switchTo != "" || *checkout != "") {
+		fmt.Fprintln(os.Stderr, "--switch and --checkout cannot be combined with --output json")
+		return exitUsage
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+	config.FlutterPath = *path
+	config.StorageBaseURL = *storageURL
+	sdk := inst.Flutter()
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if *list {
+		return listFlutterReleases(ctx, sdk, *channel, *output)
+	}
+
+	info, err := sdk.Info(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	fmt.Println(ui.Header("Flutter Version"))
+	printFlutterInfo(info)
+
+	var question string
+	var change func(ctx context.Context) error
+	switch {
+	case *switchTo != "":
+		question = fmt.Sprintf("Switch %s to the %s channel and upgrade?", info.Root, *switchTo)
+		change = func(ctx context.Context) error { return sdk.SwitchChannel(ctx, *switchTo) }
+	case *checkout != "":
+		version, err := sdk.ResolveVersion(*checkout, *channel)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+			return exitFailure
+		}
+		question = fmt.Sprintf("Check out Flutter %s in %s?", version, info.Root)
+		change = func(ctx context.Context) error { return sdk.Checkout(ctx, version) }
+	default:
+		return exitOK
+	}
+
+	if !*yes && !askYesNo(question) {
+		fmt.Println(ui.SubtleStyle.Render("\nNothing changed.\n"))
+		return exitFailure
+	}
+	fmt.Println(ui.SubtleStyle.Render("This can take a few minutes while flutter downloads the matching engine"))
+	if !reportFlutterChange(applyFlutterChange(ctx, sdk, change)) {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func sdksCommand(args []string) int {
+	fs := newFlagSet("sdks", "[--output text|json] [--root DIR] [--install VERSION [--channel CHANNEL] | --use VERSION | --remove VERSION] [--yes]")
+	output := fs.String("output", "text", "output format of the list: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	root := fs.String("root", "", "folder holding the versions/ folder and the current link (default: the data directory)")
+	install := fs.String("install", "", `install this version next to the others: "latest", a release line such as "3.22.x", or an exact version`)
+	channel := fs.String("channel", installer.DefaultChannel, "with --install, the release channel: "+strings.Join(releases.Channels, ", "))
+	use := fs.String("use", "", "make this installed version the current one")
+	remove := fs.String("remove", "", "delete this installed version, which must not be the current one")
+	storageURL := fs.String("storage-url", "", "download mirror (default: $FLUTTER_STORAGE_BASE_URL, then Google storage)")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	fs.BoolVar(yes, "y", false, "shorthand for --yes")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	actions := 0
+	for _, set := range []string{*install, *use, *remove} {
+		if set != "" {
+			actions++
+		}
+	}
+	if actions > 1 {
+		fmt.Fprintln(os.Stderr, "--install, --use and --remove cannot be combined")
+		return exitUsage
+	}
+	if actions > 0 && *output == "json" {
+		fmt.Fprintln(os.Stderr, "--install, --use and --remove cannot be combined with --output json")
+		return exitUsage
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+	config.StorageBaseURL = *storageURL
+	store := &sdks.Store{Root: *root}
+	if store.Root == "" {
+		if store, err = sdks.NewStore(); err != nil {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+			return exitFailure
+		}
+	}
+
+	switch {
+	case *install != "":
+		if !*yes && !askYesNo(fmt.Sprintf("Install Flutter %s (%s) into %s?", *install, *channel, filepath.Join(store.Root, "versions"))) {
+			fmt.Println(ui.SubtleStyle.Render("\nInstallation cancelled.\n"))
+			return exitFailure
+		}
+		if _, err := installSDKVersion(context.Background(), inst, config, store, *install, *channel, newProgressPrinter()); err != nil {
+			return exitFailure
+		}
+		return exitOK
+	case *use != "":
+		if err := useSDKVersion(inst, config, store, *use); err != nil {
+			return exitFailure
+		}
+		return exitOK
+	case *remove != "":
+		if !*yes && !askYesNo(fmt.Sprintf("Delete %s?", store.Path(*remove))) {
+			fmt.Println(ui.SubtleStyle.Render("\nNothing removed.\n"))
+			return exitFailure
+		}
+		if err := removeSDKVersion(store, *remove); err != nil {
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	list, err := store.List()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	if *output == "json" {
+		current, _ := store.Current()
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(struct {
+			Root    string     `json:"root"`
+			Current string     `json:"current"`
+			SDKs    []sdks.SDK `json:"sdks"`
+		}{store.Root, current, append([]sdks.SDK{}, list...)})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	fmt.Println(ui.Header("Flutter SDK Versions"))
+	printSDKList(store, list)
+	return exitOK
+}
+
+func syncCommand(args []string) int {
+	fs := newFlagSet("sync", "[--project DIR] [--root DIR] [--android-sdk DIR] [--yes]")
+	dir := fs.String("project", ".", "the project folder, or any folder inside it")
+	root := fs.String("root", "", "folder holding the versions/ folder and the current link (default: the data directory)")
+	androidPath := fs.String("android-sdk", "", "Android SDK directory (default: the SDK found, then the platform default)")
+	storageURL := fs.String("storage-url", "", "download mirror (default: $FLUTTER_STORAGE_BASE_URL, then Google storage)")
+	yes := fs.Bool("yes", false, "do not ask for confirmation, and accept the Android SDK licences for sdkmanager")
+	fs.BoolVar(yes, "y", false, "shorthand for --yes")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	path, err := project.Find(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	pc, err := project.Load(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+	config.StorageBaseURL = *storageURL
+	config.AndroidSDKPath = *androidPath
+	config.AcceptAndroidLicenses = *yes
+	store := &sdks.Store{Root: *root}
+	if store.Root == "" {
+		if store, err = sdks.NewStore(); err != nil {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+			return exitFailure
+		}
+	}
+	channel := pc.Channel
+	if channel == "" {
+		channel = installer.DefaultChannel
+	}
+
+	fmt.Println(ui.Header("Syncing " + filepath.Dir(path)))
+	version, err := inst.Flutter().ResolveVersion(pc.FlutterVersion, channel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	config.FlutterVersion = version
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Only the Flutter SDK and Android packages can be installed; a
+	// missing JDK or platform toolchain fails the sync with a hint
+	ready := true
+	var packages []string
+	if pc.Builds(installer.TargetAndroid) {
+		ready = checkProjectJDK(ctx, inst, pc.MinJDK) && ready
+		if packages, err = missingAndroidPackages(inst, config, pc.AndroidBuildTools); err != nil {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+			return exitFailure
+		}
+	}
+	for _, target := range pc.Targets {
+		ready = checkProjectTarget(ctx, inst, config.Platform, target) && ready
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "sync cancelled")
+		return exitFailure
+	}
+
+	var plan []string
+	if !store.Installed(version) {
+		plan = append(plan, fmt.Sprintf("Flutter %s into %s", version, store.Path(version)))
+	}
+	if len(packages) > 0 {
+		plan = append(plan, fmt.Sprintf("%s into %s", strings.Join(packages, ", "), config.AndroidSDKPath))
+	}
+	// Ctrl+C at the prompt exits as usual instead of waiting for an
+	// answer; the installs below stop on it and keep the partial download
+	stop()
+	if len(plan) > 0 && !*yes && !askYesNo("Install "+strings.Join(plan, " and ")+"?") {
+		fmt.Println(ui.SubtleStyle.Render("\nInstallation cancelled.\n"))
+		return exitFailure
+	}
+	ctx, stop = interruptContext()
+	defer stop()
+
+	if _, err := installSDKVersion(ctx, inst, config, store, version, channel, newProgressPrinter()); err != nil {
+		return exitFailure
+	}
+	if len(packages) > 0 {
+		config.AndroidPackages = packages
+		if err := installAndroidSDK(ctx, inst, config, newProgressPrinter()); err != nil {
+			return exitFailure
+		}
+	}
+
+	// The path goes last and unstyled, so scripts can take it with tail
+	fmt.Println()
+	fmt.Println(ui.NormalStyle.Render("Flutter SDK for this project:"))
+	fmt.Println(store.Path(version))
+	if !ready {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// checkProjectJDK reports whether a usable JDK of at least minMajor, or
+// jdk.MinimumMajor when that is higher, is installed
+func checkProjectJDK(ctx context.Context, inst installer.Installer, minMajor int) bool {
+	minMajor = max(minMajor, jdk.MinimumMajor)
+
+	var candidates []jdk.Install
+	for _, install := range inst.FindJDKs(ctx) {
+		if install.Info.Version.Major >= minMajor {
+			candidates = append(candidates, install)
+		}
+	}
+	best, ok := jdk.Recommend(candidates)
+	if !ok {
+		fmt.Println(ui.StatusIndicator("error", fmt.Sprintf("No JDK %d or newer found", minMajor)))
+		fmt.Println(ui.SubtleStyle.Render(fmt.Sprintf("    Install JDK %d or newer, e.g. Eclipse Temurin from https://adoptium.net/, then run: flutter-takeoff jdk --use recommended", minMajor)))
+		return false
+	}
+	fmt.Println(ui.StatusIndicator("success", fmt.Sprintf("Java %s", best.Info)) + ui.SubtleStyle.Render(" "+best.Home))
+	return true
+}
+
+// missingAndroidPackages returns the Android packages the configured
+// Flutter version needs, with build-tools raised to minBuildTools, that
+// the SDK lacks. Without an SDK, every package is missing and
+// config.AndroidSDKPath is set to the default location when it is empty
+func missingAndroidPackages(inst installer.Installer, config *installer.InstallConfig, minBuildTools string) ([]string, error) {
+	inv, err := inst.AndroidSDKInventory()
+	switch {
+	case config.AndroidSDKPath == "":
+		config.AndroidSDKPath = inst.GetDefaultAndroidSDKPath()
+		inv = &androidsdk.Inventory{Root: config.AndroidSDKPath}
+	case errors.Is(err, os.ErrNotExist):
+		inv = &androidsdk.Inventory{Root: config.AndroidSDKPath}
+	case err != nil:
+		return nil, err
+	}
+
+	req := inst.AndroidRequirements(context.Background())
+	if minBuildTools != "" && androidsdk.CompareRevisions(minBuildTools, req.BuildTools) > 0 {
+		req.BuildTools = minBuildTools
+	}
+	packages := requiredPackages(inv.Check(req))
+	if len(packages) == 0 {
+		fmt.Println(ui.StatusIndicator("success", "Android SDK") + ui.SubtleStyle.Render(" "+inv.Root))
+	} else {
+		fmt.Println(ui.StatusIndicator("warning", "Android SDK is missing "+strings.Join(packages, ", ")))
+	}
+	return packages, nil
+}
+
+// checkProjectTarget reports whether this machine has the toolchain for
+// target, by running the dependency probes it needs
+func checkProjectTarget(ctx context.Context, inst installer.Installer, platform installer.Platform, target installer.TargetPlatform) bool {
+	names, ok := targetProbes(platform, target)
+	if !ok {
+		fmt.Println(ui.StatusIndicator("error", fmt.Sprintf("%s apps cannot be built on %s", target, platform)))
+		return false
+	}
+
+	var probes []installer.Probe
+	for _, probe := range inst.Probes() {
+		if slices.Contains(names, probe.Name) {
+			probes = append(probes, probe)
+		}
+	}
+	ready := true
+	for _, dep := range installer.RunProbes(ctx, probes, nil) {
+		// The installer marks these required only for its own Target, but
+		// the project builds for target, so each of them is needed
+		dep.Required = true
+		status, text := dependencyStatus(dep)
+		fmt.Println(ui.StatusIndicator(status, dep.Name+":") + " " + ui.SubtleStyle.Render(text))
+		if !dep.IsInstalled {
+			ready = false
+			if dep.Hint != "" {
+				fmt.Println(ui.SubtleStyle.Render("    " + dep.Hint))
+			}
+		}
+	}
+	return ready
+}
+
+// targetProbes names the dependency probes building for target needs on
+// platform. ok is false when platform cannot build for target at all.
+// Android is checked separately, and web needs nothing beyond Flutter
+func targetProbes(platform installer.Platform, target installer.TargetPlatform) (names []string, ok bool) {
+	switch target {
+	case installer.TargetIOS:
+		return []string{"Xcode", "CocoaPods"}, platform == installer.PlatformMacOS
+	case installer.TargetDesktop:
+		switch platform {
+		case installer.PlatformMacOS:
+			return []string{"Xcode", "CocoaPods"}, true
+		case installer.PlatformLinux:
+			return []string{"Linux Toolchain"}, true
+		case installer.PlatformWindows:
+			return []string{"Visual Studio"}, true
+		}
+	}
+	return nil, true
+}
+
+// listFlutterReleases prints the versions released on channel, marking
+// the installed one when flutter can be run
+func listFlutterReleases(ctx context.Context, sdk *installer.FlutterSDK, channel, output string) int {
+	list, err := sdk.Releases(channel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(list); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	current := ""
+	if info, err := sdk.Info(ctx); err == nil {
+		current = info.Version
+	}
+	fmt.Println(ui.Header("Flutter " + channel + " releases"))
+	for _, r := range list {
+		fmt.Println(releaseTitle(r, current))
+	}
+	fmt.Println()
+	return exitOK
+}
+
+func androidSDKCommand(args []string) int {
+	fs := newFlagSet("android-sdk", "[--list [--output text|json]] [--path DIR] [--packages SPEC] [--yes]")
+	list := fs.Bool("list", false, "list the installed components and what Flutter is missing, without installing anything")
+	output := fs.String("output", "text", "output format of --list: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	flutterVersion := fs.String("flutter-version", installer.DefaultFlutterVersion, "Flutter version whose requirements are checked")
+	path := fs.String("path", "", "Android SDK directory (default: the platform default)")
+	packages := fs.String("packages", strings.Join(installer.DefaultAndroidPackages, ","), "comma-separated sdkmanager packages to install")
+	repository := fs.String("repository-url", "", "Android repository to download the command-line tools from (default: Google)")
+	yes := fs.Bool("yes", false, "do not ask for confirmation, and accept the Android SDK licences for sdkmanager")
+	fs.BoolVar(yes, "y", false, "shorthand for --yes")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+
+	config.AndroidSDKPath = *path
+	config.FlutterVersion = *flutterVersion
+	if *list {
+		return listAndroidSDK(inst, config, *output)
+	}
+	if config.AndroidSDKPath == "" {
+		config.AndroidSDKPath = inst.GetDefaultAndroidSDKPath()
+	}
+	config.AndroidPackages = installer.ParseAndroidPackages(*packages)
+	config.AndroidRepositoryURL = *repository
+	config.AcceptAndroidLicenses = *yes
+	if len(config.AndroidPackages) == 0 {
+		fmt.Fprintln(os.Stderr, "--packages is empty")
+		return exitUsage
+	}
+
+	if !*yes {
+		question := fmt.Sprintf("Install %s into %s?", strings.Join(config.AndroidPackages, ", "), config.AndroidSDKPath)
+		if !askYesNo(question) {
+			fmt.Println(ui.SubtleStyle.Render("\nInstallation cancelled.\n"))
+			return exitFailure
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if err := installAndroidSDK(ctx, inst, config, newProgressPrinter()); err != nil {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// listAndroidSDK prints the SDK inventory and exits 1 when a component
+// Flutter needs is missing
+func listAndroidSDK(inst installer.Installer, config *installer.InstallConfig, output string) int {
+	if output != "text" && output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", output)
+		return exitUsage
+	}
+
+	inv, err := inst.AndroidSDKInventory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	issues := inv.Check(inst.AndroidRequirements(context.Background()))
+
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(struct {
+			*androidsdk.Inventory
+			Issues []androidsdk.Issue `json:"issues"`
+		}{inv, issues})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	} else {
+		fmt.Println(ui.Header("Android SDK Components"))
+		printAndroidInventory(inv, issues)
+	}
+
+	if len(requiredPackages(issues)) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func emulatorCommand(args []string) int {
+	fs := newFlagSet("emulator", "[--output text|json] [--path DIR] [--create NAME [--image PACKAGE] [--device ID]]")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	path := fs.String("path", "", "Android SDK directory (default: the usual SDK locations)")
+	create := fs.String("create", "", "create an AVD with this name")
+	image := fs.String("image", "", `with --create, the system image package, e.g. "system-images;android-34;google_apis;x86_64" (default: the only one installed)`)
+	device := fs.String("device", defaultAVDDevice, "with --create, the device profile ID")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	if *create != "" && *output == "json" {
+		fmt.Fprintln(os.Stderr, "--create cannot be combined with --output json")
+		return exitUsage
+	}
+	if *image != "" && *create == "" {
+		fmt.Fprintln(os.Stderr, "--image needs --create")
+		return exitUsage
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+	config.AndroidSDKPath = *path
+	inv, err := inst.AndroidSDKInventory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if *create != "" {
+		return createAVD(ctx, inst, config, inv, *create, *device, *image)
+	}
+
+	status, err := inspectEmulator(ctx, inst, inv)
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(status); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	} else {
+		fmt.Println(ui.Header("Android Emulator"))
+		printEmulatorStatus(status)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	return exitOK
+}
+
+// createAVD creates an AVD from an installed system image, which may be
+// left out when only one is installed
+func createAVD(ctx context.Context, inst installer.Installer, config *installer.InstallConfig, inv *androidsdk.Inventory, name, device, image string) int {
+	images := inv.Kind(androidsdk.KindSystemImage)
+	if len(images) == 0 {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ No system images are installed; install one with: "+systemImageCommand(inv, inst.AndroidRequirements(ctx))))
+		return exitFailure
+	}
+	var paths []string
+	for _, c := range images {
+		paths = append(paths, c.Path)
+	}
+	switch {
+	case image == "" && len(paths) == 1:
+		image = paths[0]
+	case image == "":
+		fmt.Fprintln(os.Stderr, "--image is needed when several system images are installed: "+strings.Join(paths, ", "))
+		return exitUsage
+	case !slices.Contains(paths, image):
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("✗ System image %s is not installed; install it with 'flutter-takeoff android-sdk --packages %q'", image, image)))
+		return exitFailure
+	}
+
+	fmt.Println(ui.SubtleStyle.Render(fmt.Sprintf("Creating %s from %s on %s", name, device, image)))
+	if err := inst.Emulator().CreateAVD(ctx, name, device, image); err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	printAVDCreated(name)
+	return exitOK
+}
+
+func licensesCommand(args []string) int {
+	fs := newFlagSet("licenses", "[--path DIR] [--print | --yes] [--no-verify] [--repository-url URL]")
+	path := fs.String("path", "", "Android SDK directory (default: the usual SDK locations)")
+	printTexts := fs.Bool("print", false, "print the licence texts and exit")
+	yes := fs.Bool("yes", false, "accept the licences in the Android repository without reviewing them")
+	fs.BoolVar(yes, "y", false, "shorthand for --yes")
+	noVerify := fs.Bool("no-verify", false, "do not run flutter doctor to confirm the licences are accepted")
+	repository := fs.String("repository-url", "", "Android repository to fetch the licence texts from (default: Google)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *printTexts && *yes {
+		fmt.Fprintln(os.Stderr, "--print cannot be combined with --yes")
+		return exitUsage
+	}
+	if !*printTexts && !*yes && (!isTerminal(os.Stdin) || !isTerminal(os.Stdout)) {
+		fmt.Fprintln(os.Stderr, "the licences can only be reviewed in a terminal; read them with --print and accept them with --yes")
+		return exitUsage
+	}
+
+	inst, config, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+	config.AndroidSDKPath = *path
+	config.AndroidRepositoryURL = *repository
+	inv, err := inst.AndroidSDKInventory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+
+	// --yes accepts the same licences the pager shows, the ones the
+	// repository lists
+	licenses, err := inst.AndroidLicenses()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	if !*yes {
+		if *printTexts {
+			fmt.Print(licenseText(licenses))
+			return exitOK
+		}
+
+		fmt.Println(ui.Header("Android SDK Licences"))
+		printLicenseStatus(inv.Root, licenses)
+		accepted, err := showLicenses(licenses)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+			return exitFailure
+		}
+		if !accepted {
+			fmt.Println(ui.WarningStyle.Render("! Licences not accepted"))
+			return exitFailure
+		}
+	}
+
+	if err := inst.WriteAndroidLicenses(licenses); err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ "+err.Error()))
+		return exitFailure
+	}
+	fmt.Println(ui.SuccessStyle.Render("✓ Accepted the Android SDK licences in " + filepath.Join(inv.Root, "licenses")))
+	if *noVerify {
+		return exitOK
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	report, err := printFlutterDoctor(ctx, inst, false)
+	if err != nil || !checkLicenseReport(report) {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func doctorCommand(args []string) int {
+	fs := newFlagSet("doctor", "[--output text|json] [--fail-on CATEGORIES] [--strict]")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	failOn := fs.String("fail-on", "", `comma-separated category name prefixes that fail the command, e.g. "Android toolchain,Xcode", or "all"`)
+	strict := fs.Bool("strict", false, "also fail when a --fail-on category only has warnings [!]")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	inst, _, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	report, err := printFlutterDoctor(ctx, inst, *output == "text")
+	if err != nil {
+		return exitFailure
+	}
+	if *output == "json" {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+
+	failed, unknown := failingCategories(report, *failOn, *strict)
+	for _, name := range unknown {
+		fmt.Fprintln(os.Stderr, ui.WarningStyle.Render(fmt.Sprintf("! No doctor category matches %q", name)))
+	}
+	for _, c := range failed {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("✗ [%s] %s", c.Status.Symbol(), c.Name)))
+	}
+	if len(failed) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// failingCategories returns the categories selected by the comma-separated
+// failOn list that failed, counting warnings as failures when strict is
+// set, and the entries of failOn that matched no category
+func failingCategories(report *doctor.Report, failOn string, strict bool) (failed []doctor.Category, unknown []string) {
+	seen := make(map[string]bool)
+	add := func(c doctor.Category) {
+		if !seen[c.Name] && (c.Failed() || (strict && c.Status != doctor.StatusOK)) {
+			seen[c.Name] = true
+			failed = append(failed, c)
+		}
+	}
+
+	for _, name := range strings.Split(failOn, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case strings.EqualFold(name, "all"):
+			for _, c := range report.Categories {
+				add(c)
+			}
+		default:
+			c := report.Find(name)
+			if c == nil {
+				unknown = append(unknown, name)
+			} else {
+				add(*c)
+			}
+		}
+	}
+	return failed, unknown
+}
+
+func changesCommand(args []string) int {
+	fs := newFlagSet("changes", "[--output text|json]")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	switch *output {
+	case "text":
+		if err := printDoctorChanges(); err != nil {
+			return exitFailure
+		}
+	case "json":
+		before, after, err := lastDoctorRuns()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(struct {
+			Before  time.Time       `json:"before"`
+			After   time.Time       `json:"after"`
+			Changes []doctor.Change `json:"changes"`
+		}{before.Time, after.Time, doctor.Diff(before.Report, after.Report)})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// jdkJSON is one entry of the jdk command's JSON output
+type jdkJSON struct {
+	Home        string `json:"home"`
+	Source      string `json:"source"`
+	Vendor      string `json:"vendor"`
+	Version     string `json:"version"`
+	Javac       bool   `json:"javac"`
+	Usable      bool   `json:"usable"`
+	Recommended bool   `json:"recommended"`
+}
+
+func jdkCommand(args []string) int {
+	fs := newFlagSet("jdk", "[--output text|json] [--use recommended|DIR [--java-home] [--flutter]]")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	use := fs.String("use", "", `JDK to use: "recommended" or the home directory of a listed JDK`)
+	javaHome := fs.Bool("java-home", false, "with --use, only set JAVA_HOME")
+	flutter := fs.Bool("flutter", false, "with --use, only run flutter config --jdk-dir")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	if *use != "" && *output == "json" {
+		fmt.Fprintln(os.Stderr, "--use cannot be combined with --output json")
+		return exitUsage
+	}
+	if (*javaHome || *flutter) && *use == "" {
+		fmt.Fprintln(os.Stderr, "--java-home and --flutter need --use")
+		return exitUsage
+	}
+
+	inst, _, err := newCLIInstaller()
+	if err != nil {
+		return exitFailure
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	installs := inst.FindJDKs(ctx)
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "JDK search cancelled")
+		return exitFailure
+	}
+
+	if *output == "json" {
+		best, ok := jdk.Recommend(installs)
+		out := make([]jdkJSON, len(installs))
+		for i, install := range installs {
+			out[i] = jdkJSON{
+				Home:        install.Home,
+				Source:      install.Source,
+				Vendor:      install.Info.Vendor,
+				Version:     install.Info.Version.String(),
+				Javac:       install.HasJavac,
+				Usable:      install.Usable(),
+				Recommended: ok && install == best,
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		if !ok {
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	fmt.Println(ui.Header("Java JDKs"))
+	best, ok := printJDKs(installs)
+	if *use == "" {
+		if !ok {
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	var selected jdk.Install
+	if *use == "recommended" {
+		if !ok {
+			return exitFailure
+		}
+		selected = best
+	} else {
+		found := false
+		for _, install := range installs {
+			if sameDir(install.Home, *use) {
+				selected, found = install, true
+				break
+			}
+		}
+		if !found {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("✗ No JDK found at %s; use one of the directories listed above", *use)))
+			return exitFailure
+		}
+	}
+	if !selected.Usable() {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("✗ Java %s cannot build Flutter apps: %s", selected.Info, jdkProblem(selected))))
+		return exitFailure
+	}
+
+	// Without --java-home or --flutter, do both
+	if !*javaHome && !*flutter {
+		*javaHome, *flutter = true, true
+	}
+	if !useJDK(ctx, inst, selected, *javaHome, *flutter) {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// sameDir reports whether a and b name the same directory, following
+// symlinks
+func sameDir(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+func versionCommand(args []string) int {
+	fs := newFlagSet("version", "")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	printVersionInfo()
+	return exitOK
+}
+
+// askYesNo prints a confirmation prompt and reads the answer from stdin.
+// End of input counts as no, so unattended runs never hang
+func askYesNo(question string) bool {
+	fmt.Printf("%s ", ui.ConfirmPrompt(question))
+
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+// newProgressPrinter redraws the progress line in a terminal, and prints
+// a plain line every 10% or phase change when the output is a log
+func newProgressPrinter() func(percent int, status string) {
+	if isTerminal(os.Stdout) {
+		return printProgress
+	}
+
+	lastStep, lastPhase := -1, ""
+	return func(percent int, status string) {
+		phase, _, _ := strings.Cut(status, " ")
+		if percent/10 == lastStep && phase == lastPhase {
+			return
+		}
+		lastStep, lastPhase = percent/10, phase
+		fmt.Printf("[%3d%%] %s\n", percent, status)
+	}
+}
+
+// interruptContext returns a context that is cancelled on Ctrl+C, so
+// that running commands are killed before the process exits. Commands
+// that do not use it keep the default Ctrl+C handling
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"flutter_takeoff/pkg/doctor"
+	"flutter_takeoff/pkg/installer"
+	"flutter_takeoff/pkg/runner"
+)
+
+func TestFailingCategories(t *testing.T) {
+	report := &doctor.Report{Categories: []doctor.Category{
+		{Status: doctor.StatusOK, Name: "Flutter"},
+		{Status: doctor.StatusMissing, Name: "Android toolchain - develop for Android devices"},
+		{Status: doctor.StatusPartial, Name: "Xcode - develop for iOS and macOS"},
+	}}
+
+	tests := []struct {
+		failOn      string
+		strict      bool
+		wantFailed  []string
+		wantUnknown []string
+	}{
+		{"", false, nil, nil},
+		{"flutter", false, nil, nil},
+		{"android toolchain", false, []string{"Android toolchain - develop for Android devices"}, nil},
+		{"Xcode", false, nil, nil},
+		{"Xcode", true, []string{"Xcode - develop for iOS and macOS"}, nil},
+		{"all", true, []string{"Android toolchain - develop for Android devices", "Xcode - develop for iOS and macOS"}, nil},
+		{"Android, all", false, []string{"Android toolchain - develop for Android devices"}, nil},
+		{"Chrome", false, nil, []string{"Chrome"}},
+	}
+
+	for _, tt := range tests {
+		failed, unknown := failingCategories(report, tt.failOn, tt.strict)
+		var names []string
+		for _, c := range failed {
+			names = append(names, c.Name)
+		}
+		if !reflect.DeepEqual(names, tt.wantFailed) || !reflect.DeepEqual(unknown, tt.wantUnknown) {
+			t.Errorf("failingCategories(%q, %v) = %v, %v; want %v, %v", tt.failOn, tt.strict, names, unknown, tt.wantFailed, tt.wantUnknown)
+		}
+	}
+}
+
+func TestTargetProbes(t *testing.T) {
+	tests := []struct {
+		platform  installer.Platform
+		target    installer.TargetPlatform
+		wantNames []string
+		wantOK    bool
+	}{
+		{installer.PlatformMacOS, installer.TargetIOS, []string{"Xcode", "CocoaPods"}, true},
+		{installer.PlatformLinux, installer.TargetIOS, []string{"Xcode", "CocoaPods"}, false},
+		{installer.PlatformLinux, installer.TargetDesktop, []string{"Linux Toolchain"}, true},
+		{installer.PlatformWindows, installer.TargetDesktop, []string{"Visual Studio"}, true},
+		{installer.PlatformWindows, installer.TargetWeb, nil, true},
+	}
+	for _, tt := range tests {
+		names, ok := targetProbes(tt.platform, tt.target)
+		if !reflect.DeepEqual(names, tt.wantNames) || ok != tt.wantOK {
+			t.Errorf("targetProbes(%s, %s) = %v, %v; want %v, %v", tt.platform, tt.target, names, ok, tt.wantNames, tt.wantOK)
+		}
+	}
+}
+
+func TestCheckProjectTarget(t *testing.T) {
+	xcode := func() *runner.Fake {
+		return runner.NewFake().
+			On("xcode-select -p", runner.Result{Stdout: "/Applications/Xcode.app/Contents/Developer\n"}).
+			On("pod --version", runner.Result{Stdout: "1.15.2\n"})
+	}
+
+	tests := []struct {
+		name   string
+		inst   installer.Installer
+		target installer.TargetPlatform
+		want   bool
+	}{
+		{
+			name:   "ios without xcode",
+			inst:   &installer.MacOSInstaller{Runner: xcode().On("xcodebuild -version", runner.Result{ExitCode: 1})},
+			target: installer.TargetIOS,
+		},
+		{
+			name:   "ios with xcode",
+			inst:   &installer.MacOSInstaller{Runner: xcode().On("xcodebuild -version", runner.Result{Stdout: "Xcode 15.4\n"})},
+			target: installer.TargetIOS,
+			want:   true,
+		},
+		{
+			name:   "linux desktop without toolchain",
+			inst:   &installer.LinuxInstaller{Runner: runner.NewFake()},
+			target: installer.TargetDesktop,
+		},
+		{
+			name:   "windows desktop without visual studio",
+			inst:   &installer.WindowsInstaller{Runner: runner.NewFake()},
+			target: installer.TargetDesktop,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// As in sync, the installer itself is set up for Android
+			config := &installer.InstallConfig{Target: installer.TargetAndroid}
+			var platform installer.Platform
+			switch inst := tt.inst.(type) {
+			case *installer.MacOSInstaller:
+				inst.Config, platform = config, installer.PlatformMacOS
+			case *installer.LinuxInstaller:
+				inst.Config, platform = config, installer.PlatformLinux
+			case *installer.WindowsInstaller:
+				inst.Config, platform = config, installer.PlatformWindows
+			}
+			config.Platform = platform
+
+			if got := checkProjectTarget(context.Background(), tt.inst, platform, tt.target); got != tt.want {
+				t.Errorf("checkProjectTarget(%s) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
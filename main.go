@@ -2,13 +2,24 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/doctor"
+	"flutter_takeoff/pkg/download"
 	"flutter_takeoff/pkg/installer"
+	"flutter_takeoff/pkg/jdk"
+	"flutter_takeoff/pkg/releases"
+	"flutter_takeoff/pkg/sdks"
 	"flutter_takeoff/pkg/ui"
 	"flutter_takeoff/pkg/version"
 
@@ -16,17 +27,26 @@ import (
 )
 
 func main() {
+	// Subcommands run non-interactively for scripts and CI
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	// Print welcome banner
 	printBanner()
 
 	// Detect platform
 	config := &installer.InstallConfig{
-		Platform: installer.PlatformWindows,
+		Platform: installer.DetectPlatform(),
 		Target:   installer.TargetAndroid,
 	}
 
 	// Create installer
-	windowsInstaller := installer.NewWindowsInstaller(config)
+	inst, err := installer.New(config)
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
 
 	// Main menu
 	for {
@@ -34,11 +54,25 @@ func main() {
 
 		switch choice {
 		case "check":
-			checkDependencies(windowsInstaller)
+			checkDependencies(inst)
 		case "install":
-			runInstallation(windowsInstaller)
+			runInstallation(inst, config)
 		case "doctor":
-			runFlutterDoctor(windowsInstaller)
+			runFlutterDoctor(inst)
+		case "changes":
+			showDoctorChanges()
+		case "flutter":
+			manageFlutterVersion(inst)
+		case "sdks":
+			manageSDKVersions(inst, config)
+		case "android":
+			runAndroidSDKInstallation(inst, config)
+		case "emulator":
+			manageEmulators(inst, config)
+		case "licenses":
+			reviewAndroidLicenses(inst)
+		case "jdk":
+			manageJDKs(inst)
 		case "version":
 			showVersionInfo()
 		case "quit":
@@ -148,7 +182,14 @@ func showMainMenu() string {
 	items := []ui.MenuItem{
 		{Title: "Check Dependencies", Description: "Verify installed prerequisites", Value: "check"},
 		{Title: "Install Flutter SDK", Description: "Download and set up Flutter", Value: "install"},
+		{Title: "Manage Flutter Version", Description: "Switch channel or check out a release", Value: "flutter"},
+		{Title: "Flutter SDK Versions", Description: "Install versions side by side and choose the current one", Value: "sdks"},
+		{Title: "Install Android SDK", Description: "Command-line tools and SDK packages", Value: "android"},
+		{Title: "Android Emulator", Description: "List system images and AVDs, create an AVD", Value: "emulator"},
+		{Title: "Android Licences", Description: "Review and accept the Android SDK licences", Value: "licenses"},
 		{Title: "Run Flutter Doctor", Description: "Diagnose Flutter installation", Value: "doctor"},
+		{Title: "What Changed Since Last Time", Description: "Compare the last two flutter doctor runs", Value: "changes"},
+		{Title: "Java JDKs", Description: "Find installed JDKs and choose one for Flutter", Value: "jdk"},
 		{Title: "Version Info", Description: "Show version and build information", Value: "version"},
 		{Title: "Exit", Description: "Quit the installer", Value: "quit"},
 	}
@@ -169,63 +210,112 @@ func showMainMenu() string {
 	return "quit"
 }
 
-func checkDependencies(inst *installer.WindowsInstaller) {
+func checkDependencies(inst installer.Installer) {
 	fmt.Println(ui.Header("Checking Dependencies"))
 
-	deps := inst.CheckDependencies()
+	if deps, ok := detectDependencies(inst); ok {
+		printDependencySummary(deps)
+	}
 
-	fmt.Println(ui.HeaderStyle.Render("Required Dependencies:\n"))
+	waitForEnter()
+}
 
-	for _, dep := range deps {
-		status := "error"
-		statusText := "Not installed"
-
-		if dep.IsInstalled {
-			status = "success"
-			statusText = "Installed"
-			if dep.Version != "" {
-				statusText += " (" + dep.Version + ")"
-			}
+// detectDependencies runs the dependency probes concurrently behind a
+// live checklist, and returns false when the user cancelled them
+func detectDependencies(inst installer.Installer) ([]installer.Dependency, bool) {
+	probes := inst.Probes()
+	names := make([]string, len(probes))
+	for i, probe := range probes {
+		names[i] = probe.Name
+	}
+
+	var deps []installer.Dependency
+	err := ui.RunChecklist("Required Dependencies:", names, func(ctx context.Context, finish func(i int, status, detail string)) error {
+		deps = installer.RunProbes(ctx, probes, func(i int, dep installer.Dependency) {
+			status, detail := dependencyStatus(dep)
+			finish(i, status, detail)
+		})
+		return ctx.Err()
+	})
+	if err != nil {
+		fmt.Println(ui.WarningStyle.Render("! Dependency check cancelled\n"))
+		return nil, false
+	}
+	fmt.Println()
+	return deps, true
+}
+
+// dependencyStatus returns the StatusIndicator status and text for dep
+func dependencyStatus(dep installer.Dependency) (string, string) {
+	if !dep.IsInstalled {
+		text := "Not installed"
+		if dep.Problem != "" {
+			text = dep.Problem
+		}
+		if !dep.Required {
+			return "warning", text
 		}
+		return "error", text
+	}
+
+	text := "Installed"
+	if dep.Version != "" {
+		text += " (" + dep.Version + ")"
+	}
+	if dep.Problem != "" {
+		return "warning", text + " - " + dep.Problem
+	}
+	return "success", text
+}
+
+// printDependencyReport lists each dependency and reports whether all
+// required ones are installed
+func printDependencyReport(deps []installer.Dependency) bool {
+	fmt.Println(ui.HeaderStyle.Render("Required Dependencies:\n"))
 
+	for _, dep := range deps {
+		status, statusText := dependencyStatus(dep)
 		fmt.Printf("%s %s\n",
 			ui.StatusIndicator(status, dep.Name+":"),
 			ui.SubtleStyle.Render(statusText))
-
-		if !dep.IsInstalled && dep.Required {
-			fmt.Println(ui.SubtleStyle.Render("  → " + dep.Description))
-		}
 	}
 
 	fmt.Println()
+	return printDependencySummary(deps)
+}
 
-	// Check if all required dependencies are installed
+// printDependencySummary explains how to install each missing required
+// dependency and reports whether all required ones are installed
+func printDependencySummary(deps []installer.Dependency) bool {
 	allInstalled := true
 	for _, dep := range deps {
 		if dep.Required && !dep.IsInstalled {
 			allInstalled = false
-			break
+			fmt.Println(ui.StatusIndicator("error", dep.Name) + ui.SubtleStyle.Render(" → "+dep.Description))
+			if dep.Hint != "" {
+				fmt.Println(ui.SubtleStyle.Render("    " + dep.Hint))
+			}
 		}
 	}
 
 	if allInstalled {
 		fmt.Println(ui.SuccessStyle.Render("✓ All required dependencies are installed!\n"))
 	} else {
-		fmt.Println(ui.WarningStyle.Render("⚠ Some dependencies are missing\n"))
-		fmt.Println(ui.SubtleStyle.Render("Installation Guide:"))
-		fmt.Println(ui.SubtleStyle.Render("  • Git: https://git-scm.com/download/win"))
-		fmt.Println(ui.SubtleStyle.Render("  • Java JDK: https://adoptium.net/"))
-		fmt.Println(ui.SubtleStyle.Render("  • Android SDK: Install Android Studio or use command-line tools\n"))
+		fmt.Println(ui.WarningStyle.Render("\n⚠ Some dependencies are missing\n"))
 	}
 
-	waitForEnter()
+	return allInstalled
 }
 
-func runInstallation(inst *installer.WindowsInstaller) {
+func runInstallation(inst installer.Installer, config *installer.InstallConfig) {
 	fmt.Println(ui.Header("Flutter SDK Installation"))
 
 	// Check if Flutter is already installed
-	deps := inst.CheckDependencies()
+	deps, ok := detectDependencies(inst)
+	if !ok {
+		waitForEnter()
+		return
+	}
 	var flutterDep installer.Dependency
 	for _, dep := range deps {
 		if dep.Name == "Flutter SDK" {
@@ -305,18 +395,16 @@ func runInstallation(inst *installer.WindowsInstaller) {
 		selectedPath = defaultPath
 	}
 
-	inst.Config.FlutterPath = selectedPath
+	config.FlutterPath = selectedPath
 	fmt.Printf("\n%s %s\n\n",
 		ui.SuccessStyle.Render("✓ Installation path set to:"),
-		inst.Config.FlutterPath)
+		config.FlutterPath)
 
 	// Installation steps menu
 	fmt.Println(ui.HeaderStyle.Render("Installation Steps:\n"))
 	fmt.Println(ui.Checkbox(true, "Download Flutter SDK"))
 	fmt.Println(ui.Checkbox(true, "Extract to installation path"))
 	fmt.Println(ui.Checkbox(true, "Add Flutter to PATH"))
-	fmt.Println(ui.Checkbox(true, "Accept Android licenses"))
-	fmt.Println(ui.Checkbox(true, "Run flutter doctor"))
 
 	fmt.Printf("\n%s ", ui.ConfirmPrompt("Continue with installation?"))
 	response, _ := reader.ReadString('\n')
@@ -327,26 +415,27 @@ func runInstallation(inst *installer.WindowsInstaller) {
 		return
 	}
 
-	// Simulate installation process
+	installFlutter(context.Background(), inst, config, printProgress)
+
+	waitForEnter()
+}
+
+// installFlutter downloads and extracts the SDK and puts it on PATH
+func installFlutter(ctx context.Context, inst installer.Installer, config *installer.InstallConfig, progress func(percent int, status string)) error {
 	fmt.Println(ui.Header("Installing Flutter SDK"))
 
-	steps := []struct {
-		name    string
-		percent int
-	}{
-		{"Preparing installation...", 10},
-		{"Downloading Flutter SDK (this may take a few minutes)...", 30},
-		{"Extracting files...", 60},
-		{"Setting up environment...", 80},
-		{"Configuring PATH...", 90},
-		{"Finalizing installation...", 100},
+	err := inst.DownloadFlutter(ctx, progress)
+	fmt.Println()
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		return err
 	}
 
-	for _, step := range steps {
-		fmt.Printf("\r%s %s",
-			ui.SimpleProgressBar(step.percent, 40),
-			ui.SubtleStyle.Render(step.name))
-		// In real implementation, this would actually download and install
+	if msg, err := inst.SetupEnvironmentPath(); err != nil {
+		fmt.Println(ui.WarningStyle.Render("⚠ Could not add Flutter to PATH: " + err.Error()))
+		fmt.Println(ui.SubtleStyle.Render("  Add " + filepath.Join(config.FlutterPath, "bin") + " to PATH manually"))
+	} else {
+		fmt.Println(ui.SuccessStyle.Render("✓ " + msg))
 	}
 
 	fmt.Println()
@@ -356,26 +445,1119 @@ func runInstallation(inst *installer.WindowsInstaller) {
 	fmt.Println(ui.SubtleStyle.Render("1. Restart your terminal/command prompt"))
 	fmt.Println(ui.SubtleStyle.Render("2. Run 'flutter doctor' to verify installation"))
 	fmt.Println(ui.SubtleStyle.Render("3. Accept Android licenses with 'flutter doctor --android-licenses'\n"))
+	return nil
+}
 
+func manageFlutterVersion(inst installer.Installer) {
+	fmt.Println(ui.Header("Flutter Version"))
+
+	sdk := inst.Flutter()
+	var info *installer.FlutterInfo
+	err := ui.RunTask("Reading the Flutter version...", func(ctx context.Context) error {
+		var err error
+		info, err = sdk.Info(ctx)
+		return err
+	})
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		fmt.Println(ui.SubtleStyle.Render("    Install Flutter first, or make sure it is on PATH"))
+		fmt.Println()
+		waitForEnter()
+		return
+	}
+	printFlutterInfo(info)
+
+	var question, title string
+	var change func(ctx context.Context) error
+	switch showMenu("What would you like to change?", []ui.MenuItem{
+		{Title: "Switch channel", Value: "channel"},
+		{Title: "Check out a specific version", Value: "version"},
+		{Title: "Back", Value: "back"},
+	}) {
+	case "channel":
+		var items []ui.MenuItem
+		for _, c := range installer.FlutterChannels {
+			if c != "master" && c != info.Channel {
+				items = append(items, ui.MenuItem{Title: c, Value: c})
+			}
+		}
+		channel := showMenu("Switch to which channel?", items)
+		if channel == "" {
+			return
+		}
+		question = fmt.Sprintf("Switch to the %s channel and run flutter upgrade?", channel)
+		title = "Switching to " + channel + "..."
+		change = func(ctx context.Context) error { return sdk.SwitchChannel(ctx, channel) }
+
+	case "version":
+		version, _ := pickFlutterRelease(sdk, info.Version)
+		if version == "" {
+			return
+		}
+		question = fmt.Sprintf("Check out Flutter %s in %s?", version, info.Root)
+		title = "Checking out " + version + "..."
+		change = func(ctx context.Context) error { return sdk.Checkout(ctx, version) }
+
+	default:
+		return
+	}
+
+	if !askYesNo(question) {
+		fmt.Println(ui.SubtleStyle.Render("\nNothing changed.\n"))
+		return
+	}
+	err = ui.RunTask(title, func(ctx context.Context) error {
+		var err error
+		info, err = applyFlutterChange(ctx, sdk, change)
+		return err
+	})
+	reportFlutterChange(info, err)
+	fmt.Println()
 	waitForEnter()
 }
 
-func runFlutterDoctor(inst *installer.WindowsInstaller) {
-	fmt.Println(ui.Header("Running Flutter Doctor"))
+func manageSDKVersions(inst installer.Installer, config *installer.InstallConfig) {
+	fmt.Println(ui.Header("Flutter SDK Versions"))
+
+	store, err := sdks.NewStore()
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		waitForEnter()
+		return
+	}
+	var list []sdks.SDK
+	err = ui.RunTask("Measuring the installed SDKs...", func(ctx context.Context) error {
+		var err error
+		list, err = store.List()
+		return err
+	})
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		waitForEnter()
+		return
+	}
+	printSDKList(store, list)
+
+	items := []ui.MenuItem{{Title: "Install another version", Value: "install"}}
+	if len(list) > 1 {
+		items = append(items,
+			ui.MenuItem{Title: "Switch the current version", Value: "use"},
+			ui.MenuItem{Title: "Remove a version", Value: "remove"})
+	} else if len(list) == 1 && !list[0].Current {
+		items = append(items, ui.MenuItem{Title: "Make " + list[0].Version + " current", Value: "use"})
+	}
+	items = append(items, ui.MenuItem{Title: "Back", Value: "back"})
+
+	switch showMenu("What would you like to do?", items) {
+	case "install":
+		version, channel := pickFlutterRelease(inst.Flutter(), "")
+		if version == "" {
+			return
+		}
+		if !askYesNo(fmt.Sprintf("Install Flutter %s into %s?", version, store.Path(version))) {
+			fmt.Println(ui.SubtleStyle.Render("\nInstallation cancelled.\n"))
+			return
+		}
+		installSDKVersion(context.Background(), inst, config, store, version, channel, printProgress)
+
+	case "use":
+		var versions []ui.MenuItem
+		for _, sdk := range list {
+			if !sdk.Current {
+				versions = append(versions, ui.MenuItem{Title: sdk.Version, Value: sdk.Version})
+			}
+		}
+		version := versions[0].Value
+		if len(versions) > 1 {
+			if version = showMenu("Make which version current?", versions); version == "" {
+				return
+			}
+		}
+		useSDKVersion(inst, config, store, version)
+
+	case "remove":
+		var versions []ui.MenuItem
+		for _, sdk := range list {
+			if !sdk.Current {
+				versions = append(versions, ui.MenuItem{Title: fmt.Sprintf("%-18s %s", sdk.Version, download.FormatBytes(sdk.Size)), Value: sdk.Version})
+			}
+		}
+		version := showMenu("Remove which version?", versions)
+		if version == "" || !askYesNo(fmt.Sprintf("Delete %s?", store.Path(version))) {
+			fmt.Println()
+			return
+		}
+		removeSDKVersion(store, version)
+
+	default:
+		return
+	}
+	fmt.Println()
+	waitForEnter()
+}
+
+// printSDKList lists the SDKs in the store with their sizes, marking the
+// current one
+func printSDKList(store *sdks.Store, list []sdks.SDK) {
+	fmt.Printf("%s %s\n\n", ui.NormalStyle.Render("SDK versions:"), store.Root)
+	if len(list) == 0 {
+		fmt.Println(ui.SubtleStyle.Render("  none installed yet"))
+		fmt.Println()
+		return
+	}
+
+	current := false
+	for _, sdk := range list {
+		line := fmt.Sprintf("%-18s %10s", sdk.Version, download.FormatBytes(sdk.Size))
+		if sdk.Current {
+			current = true
+			fmt.Println("  " + ui.StatusIndicator("success", line) + ui.SubtleStyle.Render(" (current)"))
+		} else {
+			fmt.Println("    " + line)
+		}
+	}
+	fmt.Println()
+	if !current {
+		fmt.Println(ui.WarningStyle.Render("! No version is current; choose one to put it on PATH"))
+		fmt.Println()
+	}
+}
+
+// installSDKVersion resolves query on channel and installs that release
+// next to the other versions in the store, making it current when no
+// version is yet. It returns the version installed
+func installSDKVersion(ctx context.Context, inst installer.Installer, config *installer.InstallConfig, store *sdks.Store, query, channel string, progress func(percent int, status string)) (string, error) {
+	version, err := inst.Flutter().ResolveVersion(query, channel)
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		return "", err
+	}
+
+	if store.Installed(version) {
+		fmt.Println(ui.SuccessStyle.Render("✓ Flutter " + version + " is already installed in " + store.Path(version)))
+	} else {
+		config.FlutterPath = store.Path(version)
+		config.FlutterVersion = version
+		config.Channel = channel
+		fmt.Println(ui.Header("Installing Flutter " + version))
+		err := inst.DownloadFlutter(ctx, progress)
+		fmt.Println()
+		if err != nil {
+			fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+			return "", err
+		}
+		fmt.Println(ui.SuccessStyle.Render("✓ Installed Flutter " + version + " in " + store.Path(version)))
+	}
+
+	if current, err := store.Current(); err == nil && current == "" {
+		if err := useSDKVersion(inst, config, store, version); err != nil {
+			return "", err
+		}
+	}
+	return version, nil
+}
+
+// useSDKVersion makes version the current SDK and puts the current link
+// on PATH
+func useSDKVersion(inst installer.Installer, config *installer.InstallConfig, store *sdks.Store, version string) error {
+	if err := store.Use(version); err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		return err
+	}
+	fmt.Println(ui.SuccessStyle.Render("✓ Flutter " + version + " is now the current SDK"))
+
+	config.FlutterPath = store.CurrentPath()
+	if msg, err := inst.SetupEnvironmentPath(); err != nil {
+		fmt.Println(ui.WarningStyle.Render("⚠ Could not add Flutter to PATH: " + err.Error()))
+		fmt.Println(ui.SubtleStyle.Render("  Add " + filepath.Join(config.FlutterPath, "bin") + " to PATH manually"))
+	} else {
+		fmt.Println(ui.SuccessStyle.Render("✓ " + msg))
+	}
+	fmt.Println(ui.SubtleStyle.Render("  New terminals run Flutter " + version + " from " + filepath.Join(config.FlutterPath, "bin")))
+	return nil
+}
+
+// removeSDKVersion deletes a version that is not current and reports the
+// space it freed
+func removeSDKVersion(store *sdks.Store, version string) error {
+	freed, err := store.Remove(version)
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		return err
+	}
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✓ Removed Flutter %s, freeing %s", version, download.FormatBytes(freed))))
+	return nil
+}
+
+// pickFlutterRelease lets the user choose a channel and then one of its
+// releases from the manifest, marking the installed version, and returns
+// the version and its channel, or "" when the user backed out
+func pickFlutterRelease(sdk *installer.FlutterSDK, installed string) (string, string) {
+	var items []ui.MenuItem
+	for _, c := range releases.Channels {
+		items = append(items, ui.MenuItem{Title: c, Value: c})
+	}
+	channel := showMenu("Releases from which channel?", items)
+	if channel == "" {
+		return "", ""
+	}
+
+	var list []releases.Release
+	err := ui.RunTask("Fetching the "+channel+" releases...", func(ctx context.Context) error {
+		var err error
+		list, err = sdk.Releases(channel)
+		return err
+	})
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		return "", ""
+	}
+	if len(list) == 0 {
+		fmt.Println(ui.WarningStyle.Render("! The manifest lists no " + channel + " releases\n"))
+		return "", ""
+	}
+
+	items = items[:0]
+	for _, r := range list {
+		items = append(items, ui.MenuItem{Title: releaseTitle(r, installed), Value: r.Version})
+	}
+	return showMenu("Which version?", items), channel
+}
+
+// showMenu shows a menu and returns the value of the chosen item, or ""
+// when the user quit it
+func showMenu(title string, items []ui.MenuItem) string {
+	finalModel, err := tea.NewProgram(ui.NewMenu(title, items, 60, 15)).Run()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return ""
+	}
+	if menuModel, ok := finalModel.(ui.MenuModel); ok {
+		return menuModel.Choice()
+	}
+	return ""
+}
+
+// printFlutterInfo shows the channel and revisions of a Flutter SDK
+func printFlutterInfo(info *installer.FlutterInfo) {
+	fmt.Printf("%s %s\n\n", ui.NormalStyle.Render("Flutter SDK:"), info.Root)
+	fmt.Printf("  %-12s %s\n", "Channel", info.Channel)
+	fmt.Printf("  %-12s %s\n", "Version", info.Version)
+	fmt.Printf("  %-12s %s %s\n", "Framework", shortRevision(info.FrameworkRevision), ui.SubtleStyle.Render(info.FrameworkDate))
+	fmt.Printf("  %-12s %s\n", "Engine", shortRevision(info.EngineRevision))
+	fmt.Printf("  %-12s %s\n", "Dart", info.DartVersion)
+	fmt.Println()
+}
+
+// shortRevision abbreviates a git revision the way flutter --version does
+func shortRevision(rev string) string {
+	if len(rev) > 10 {
+		return rev[:10]
+	}
+	return rev
+}
+
+// releaseTitle describes a release on one line, marking the installed
+// version
+func releaseTitle(r releases.Release, installed string) string {
+	title := fmt.Sprintf("%-18s %s  Dart %s", r.Version, r.ReleaseDate.Format("2006-01-02"), r.DartSDKVersion)
+	if r.Version == installed {
+		title += " (installed)"
+	}
+	return title
+}
+
+// applyFlutterChange switches the SDK's channel or version and reads the
+// version it ended up on
+func applyFlutterChange(ctx context.Context, sdk *installer.FlutterSDK, change func(ctx context.Context) error) (*installer.FlutterInfo, error) {
+	if err := change(ctx); err != nil {
+		return nil, err
+	}
+	return sdk.Info(ctx)
+}
+
+// reportFlutterChange prints the outcome of applyFlutterChange and
+// reports whether it worked
+func reportFlutterChange(info *installer.FlutterInfo, err error) bool {
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		return false
+	}
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✓ Flutter is now %s on the %s channel (Dart %s)", info.Version, info.Channel, info.DartVersion)))
+	return true
+}
+
+func runAndroidSDKInstallation(inst installer.Installer, config *installer.InstallConfig) {
+	fmt.Println(ui.Header("Android SDK Installation"))
+
+	// With an SDK in place, offer only what it is missing
+	packages := config.AndroidPackages
+	if inv, err := inst.AndroidSDKInventory(); err == nil {
+		issues := inv.Check(inst.AndroidRequirements(context.Background()))
+		printAndroidInventory(inv, issues)
+		if len(packages) == 0 {
+			packages = requiredPackages(issues)
+			if len(packages) == 0 {
+				fmt.Println(ui.SuccessStyle.Render("✓ The Android SDK has everything Flutter needs"))
+				fmt.Println()
+				waitForEnter()
+				return
+			}
+		}
+	}
+	if config.AndroidSDKPath == "" {
+		config.AndroidSDKPath = inst.GetDefaultAndroidSDKPath()
+	}
+	if len(packages) == 0 {
+		packages = installer.DefaultAndroidPackages
+	}
+	config.AndroidPackages = packages
+
+	fmt.Printf("%s %s\n", ui.NormalStyle.Render("Android SDK path:"), config.AndroidSDKPath)
+	fmt.Printf("%s %s\n\n", ui.NormalStyle.Render("Packages:"), strings.Join(packages, ", "))
+
+	if !askYesNo("Install the Android command-line tools and these packages?") {
+		fmt.Println(ui.SubtleStyle.Render("\nInstallation cancelled.\n"))
+		waitForEnter()
+		return
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	installAndroidSDK(ctx, inst, config, printProgress)
+	waitForEnter()
+}
+
+// androidKinds are the component kinds in the order they are listed
+var androidKinds = []struct{ kind, title string }{
+	{androidsdk.KindPlatformTools, "Platform tools"},
+	{androidsdk.KindCmdlineTools, "Command-line tools"},
+	{androidsdk.KindBuildTools, "Build tools"},
+	{androidsdk.KindPlatform, "Platforms"},
+	{androidsdk.KindNDK, "NDK"},
+	{androidsdk.KindEmulator, "Emulator"},
+	{androidsdk.KindSystemImage, "System images"},
+}
+
+// printAndroidInventory lists the SDK components by kind, followed by
+// what is missing
+func printAndroidInventory(inv *androidsdk.Inventory, issues []androidsdk.Issue) {
+	fmt.Printf("%s %s\n\n", ui.NormalStyle.Render("Android SDK:"), inv.Root)
+
+	for _, k := range androidKinds {
+		var names []string
+		for _, c := range inv.Kind(k.kind) {
+			// "build-tools;34.0.0" is listed as 34.0.0, "platform-tools" by revision
+			_, name, found := strings.Cut(c.Path, ";")
+			switch {
+			case !found:
+				name = c.Revision
+			case name != c.Revision && k.kind != androidsdk.KindPlatform && k.kind != androidsdk.KindSystemImage:
+				name += " (" + c.Revision + ")"
+			}
+			names = append(names, name)
+		}
+
+		text := strings.Join(names, ", ")
+		if len(names) == 0 {
+			text = "-"
+		}
+		fmt.Printf("  %-20s %s\n", k.title, ui.SubtleStyle.Render(text))
+	}
+	fmt.Println()
+
+	for _, issue := range issues {
+		status := "warning"
+		if issue.Required {
+			status = "error"
+		}
+		fmt.Println(ui.StatusIndicator(status, issue.Message) + ui.SubtleStyle.Render(" → "+issue.Package))
+	}
+	if len(issues) > 0 {
+		fmt.Println()
+	}
+}
+
+// requiredPackages returns the packages that fix the required issues
+func requiredPackages(issues []androidsdk.Issue) []string {
+	var packages []string
+	for _, issue := range issues {
+		if issue.Required {
+			packages = append(packages, issue.Package)
+		}
+	}
+	return packages
+}
+
+// installAndroidSDK installs the command-line tools and SDK packages into
+// config.AndroidSDKPath
+func installAndroidSDK(ctx context.Context, inst installer.Installer, config *installer.InstallConfig, progress func(percent int, status string)) error {
+	fmt.Println(ui.Header("Installing Android SDK"))
+
+	// sdkmanager prints its own progress and licence prompts, so a
+	// finished step must not leave the cursor on a redrawn progress line
+	redrawn := isTerminal(os.Stdout)
+	err := inst.InstallAndroidSDK(ctx, func(percent int, status string) {
+		progress(percent, status)
+		if percent == 100 && redrawn {
+			fmt.Println()
+		}
+	})
+	fmt.Println()
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		return err
+	}
+
+	fmt.Println(ui.SuccessStyle.Render("✓ Android SDK installed to " + config.AndroidSDKPath + "\n"))
+	return nil
+}
+
+func manageEmulators(inst installer.Installer, config *installer.InstallConfig) {
+	fmt.Println(ui.Header("Android Emulator"))
+
+	inv, err := inst.AndroidSDKInventory()
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		fmt.Println()
+		waitForEnter()
+		return
+	}
+
+	var status emulatorStatus
+	var listErr error
+	err = ui.RunTask("Looking for emulators...", func(ctx context.Context) error {
+		status, listErr = inspectEmulator(ctx, inst, inv)
+		return ctx.Err()
+	})
+	if err != nil {
+		fmt.Println(ui.WarningStyle.Render("! Emulator search cancelled\n"))
+		waitForEnter()
+		return
+	}
+	printEmulatorStatus(status)
+	if listErr != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + listErr.Error()))
+		fmt.Println()
+		waitForEnter()
+		return
+	}
+	if len(status.SystemImages) == 0 {
+		fmt.Println(ui.SubtleStyle.Render("Install a system image to create an AVD: " + systemImageCommand(inv, inst.AndroidRequirements(context.Background()))))
+		fmt.Println()
+		waitForEnter()
+		return
+	}
+
+	if !askYesNo("Create a new AVD?") {
+		fmt.Println()
+		return
+	}
 
-	output, err := inst.RunFlutterDoctor()
+	reader := bufio.NewReader(os.Stdin)
+	image := status.SystemImages[len(status.SystemImages)-1].Path
+	if len(status.SystemImages) > 1 {
+		fmt.Printf("%s ", ui.SubtleStyle.Render(fmt.Sprintf("System image (1-%d, Enter for %d):", len(status.SystemImages), len(status.SystemImages))))
+		choice, _ := reader.ReadString('\n')
+		if choice = strings.TrimSpace(choice); choice != "" {
+			n, err := strconv.Atoi(choice)
+			if err != nil || n < 1 || n > len(status.SystemImages) {
+				fmt.Println(ui.WarningStyle.Render("Invalid choice, no AVD created\n"))
+				waitForEnter()
+				return
+			}
+			image = status.SystemImages[n-1].Path
+		}
+	}
+
+	emulator := inst.Emulator()
+	var devices []string
+	err = ui.RunTask("Loading device profiles...", func(ctx context.Context) error {
+		var err error
+		devices, err = emulator.ListDevices(ctx)
+		return err
+	})
 	if err != nil {
-		fmt.Println(ui.ErrorStyle.Render("✗ Failed to run flutter doctor"))
-		fmt.Println(ui.SubtleStyle.Render("  Error: " + err.Error()))
-		fmt.Println(ui.SubtleStyle.Render("\n  Make sure Flutter is installed and added to PATH\n"))
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		waitForEnter()
+		return
+	}
+	if len(devices) == 0 {
+		fmt.Println(ui.ErrorStyle.Render("✗ avdmanager lists no device profiles\n"))
+		waitForEnter()
+		return
+	}
+
+	device := devices[0]
+	for _, d := range devices {
+		if d == defaultAVDDevice {
+			device = d
+		}
+	}
+	fmt.Println(ui.SubtleStyle.Render("Device profiles: " + strings.Join(devices, ", ")))
+	fmt.Printf("%s ", ui.SubtleStyle.Render("Device profile (Enter for "+device+"):"))
+	if choice, _ := reader.ReadString('\n'); strings.TrimSpace(choice) != "" {
+		device = strings.TrimSpace(choice)
+		if !slices.Contains(devices, device) {
+			fmt.Println(ui.WarningStyle.Render(fmt.Sprintf("Unknown device profile %q, no AVD created\n", device)))
+			waitForEnter()
+			return
+		}
+	}
+
+	name := defaultAVDName(device, image)
+	fmt.Printf("%s ", ui.SubtleStyle.Render("AVD name (Enter for "+name+"):"))
+	if choice, _ := reader.ReadString('\n'); strings.TrimSpace(choice) != "" {
+		name = strings.TrimSpace(choice)
+	}
+
+	err = ui.RunTask("Creating "+name+"...", func(ctx context.Context) error {
+		return emulator.CreateAVD(ctx, name, device, image)
+	})
+	fmt.Println()
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
 	} else {
+		printAVDCreated(name)
+	}
+	waitForEnter()
+}
+
+// defaultAVDDevice is the device profile offered when creating an AVD
+const defaultAVDDevice = "pixel_7"
+
+// emulatorStatus is what the emulator screen and command report
+type emulatorStatus struct {
+	SDK          string                 `json:"sdk"`
+	Acceleration installer.Acceleration `json:"acceleration"`
+	SystemImages []androidsdk.Component `json:"system_images"`
+	AVDs         []installer.AVD        `json:"avds"`
+}
+
+// inspectEmulator checks hardware acceleration and lists the system
+// images and AVDs. The status is filled in as far as possible when
+// avdmanager cannot list the AVDs
+func inspectEmulator(ctx context.Context, inst installer.Installer, inv *androidsdk.Inventory) (emulatorStatus, error) {
+	emulator := inst.Emulator()
+	status := emulatorStatus{
+		SDK:          inv.Root,
+		Acceleration: emulator.CheckAcceleration(ctx),
+		SystemImages: append([]androidsdk.Component{}, inv.Kind(androidsdk.KindSystemImage)...),
+		AVDs:         []installer.AVD{},
+	}
+	avds, err := emulator.ListAVDs(ctx)
+	if err != nil {
+		return status, err
+	}
+	status.AVDs = append(status.AVDs, avds...)
+	return status, nil
+}
+
+// printEmulatorStatus shows whether the emulator can be accelerated,
+// the numbered system images and the AVDs
+func printEmulatorStatus(status emulatorStatus) {
+	fmt.Printf("%s %s\n\n", ui.NormalStyle.Render("Android SDK:"), status.SDK)
+
+	accel := status.Acceleration
+	if accel.Available {
+		fmt.Println(ui.StatusIndicator("success", "Hardware acceleration: "+accel.Hypervisor))
+	} else {
+		fmt.Println(ui.StatusIndicator("warning", "Hardware acceleration unavailable ("+accel.Hypervisor+"); the emulator will be very slow"))
+	}
+	if accel.Detail != "" {
+		fmt.Println(ui.SubtleStyle.Render("    " + accel.Detail))
+	}
+	fmt.Println()
+
+	fmt.Println(ui.NormalStyle.Render("System images:"))
+	if len(status.SystemImages) == 0 {
+		fmt.Println(ui.WarningStyle.Render("  ! No system images are installed"))
+	}
+	for i, image := range status.SystemImages {
+		fmt.Printf("%2d. %s\n", i+1, image.Path)
+	}
+	fmt.Println()
+
+	fmt.Println(ui.NormalStyle.Render("AVDs:"))
+	if len(status.AVDs) == 0 {
+		fmt.Println(ui.SubtleStyle.Render("  none"))
+	}
+	for _, avd := range status.AVDs {
+		if avd.Error != "" {
+			fmt.Println("  " + ui.StatusIndicator("error", avd.Name) + ui.SubtleStyle.Render(" - "+avd.Error))
+			continue
+		}
+		var details []string
+		for _, d := range []string{avd.Device, avd.ABI} {
+			if d != "" {
+				details = append(details, d)
+			}
+		}
+		fmt.Println("  " + ui.StatusIndicator("success", avd.Name) + ui.SubtleStyle.Render(" "+strings.Join(details, ", ")))
+	}
+	fmt.Println()
+}
+
+// defaultAVDName names an AVD after its device and API level the way
+// Android Studio does, e.g. Pixel_7_API_34
+func defaultAVDName(device, image string) string {
+	parts := strings.Split(device, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	name := strings.Join(parts, "_")
+
+	// system-images;android-34;google_apis;x86_64
+	if fields := strings.Split(image, ";"); len(fields) > 1 {
+		if api, ok := strings.CutPrefix(fields[1], "android-"); ok {
+			name += "_API_" + api
+		}
+	}
+	return name
+}
+
+// systemImageCommand returns the command that installs the system image
+// suggested by req
+func systemImageCommand(inv *androidsdk.Inventory, req androidsdk.Requirements) string {
+	for _, issue := range inv.Check(req) {
+		if strings.HasPrefix(issue.Package, androidsdk.KindSystemImage+";") {
+			return fmt.Sprintf("flutter-takeoff android-sdk --packages %q", issue.Package)
+		}
+	}
+	return "flutter-takeoff android-sdk --packages <system image>"
+}
+
+// printAVDCreated reports a new AVD and how to start it
+func printAVDCreated(name string) {
+	fmt.Println(ui.SuccessStyle.Render("✓ Created AVD " + name))
+	fmt.Println(ui.SubtleStyle.Render("    Start it with: flutter emulators --launch " + name))
+	fmt.Println()
+}
+
+func reviewAndroidLicenses(inst installer.Installer) {
+	fmt.Println(ui.Header("Android SDK Licences"))
+
+	inv, err := inst.AndroidSDKInventory()
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		fmt.Println()
+		waitForEnter()
+		return
+	}
+
+	var licenses []androidsdk.License
+	err = ui.RunTask("Fetching the licence texts...", func(ctx context.Context) error {
+		var err error
+		licenses, err = inst.AndroidLicenses()
+		return err
+	})
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+		fmt.Println(ui.SubtleStyle.Render("    'flutter doctor --android-licenses' shows them through sdkmanager instead"))
+		fmt.Println()
+		waitForEnter()
+		return
+	}
+	printLicenseStatus(inv.Root, licenses)
+
+	if !askYesNo("Review and accept these licences?") {
+		fmt.Println()
+		return
+	}
+	accepted, err := showLicenses(licenses)
+	if err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		waitForEnter()
+		return
+	}
+	if !accepted {
+		fmt.Println(ui.WarningStyle.Render("! Licences not accepted\n"))
+		waitForEnter()
+		return
+	}
+
+	if err := inst.WriteAndroidLicenses(licenses); err != nil {
+		fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error() + "\n"))
+		waitForEnter()
+		return
+	}
+	fmt.Println(ui.SuccessStyle.Render("✓ Accepted the Android SDK licences in " + filepath.Join(inv.Root, "licenses")))
+
+	var report *doctor.Report
+	var output string
+	err = ui.RunTask("Confirming with flutter doctor...", func(ctx context.Context) error {
+		var err error
+		report, output, err = runDoctor(ctx, inst)
+		return err
+	})
+	if reportDoctorRun(report, output, err, false) == nil {
+		checkLicenseReport(report)
+	}
+	fmt.Println()
+	waitForEnter()
+}
+
+// printLicenseStatus lists the licences and whether the SDK at root
+// already has them accepted
+func printLicenseStatus(root string, licenses []androidsdk.License) {
+	fmt.Printf("%s %s\n\n", ui.NormalStyle.Render("Android SDK:"), root)
+	for _, l := range licenses {
+		if androidsdk.LicenseAccepted(root, l) {
+			fmt.Println("  " + ui.StatusIndicator("success", l.ID) + ui.SubtleStyle.Render(" accepted"))
+		} else {
+			fmt.Println("  " + ui.StatusIndicator("warning", l.ID) + ui.SubtleStyle.Render(" not accepted"))
+		}
+	}
+	fmt.Println()
+}
+
+// licenseText joins the licences into one document, each under its ID
+func licenseText(licenses []androidsdk.License) string {
+	var b strings.Builder
+	for i, l := range licenses {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(l.ID + "\n" + strings.Repeat("─", len(l.ID)) + "\n\n" + l.Text + "\n")
+	}
+	return b.String()
+}
+
+// showLicenses shows the licence texts in a full-screen pager and
+// reports whether the user accepted them
+func showLicenses(licenses []androidsdk.License) (bool, error) {
+	pager := ui.NewPager("Android SDK Licences", licenseText(licenses))
+	final, err := tea.NewProgram(pager, tea.WithAltScreen()).Run()
+	if err != nil {
+		return false, err
+	}
+	return final.(ui.PagerModel).Accepted(), nil
+}
+
+// checkLicenseReport reports whether flutter doctor now sees the Android
+// licences as accepted
+func checkLicenseReport(report *doctor.Report) bool {
+	problem, found := report.AndroidLicenseProblem()
+	switch {
+	case !found:
+		fmt.Println(ui.WarningStyle.Render("! flutter doctor did not check the Android toolchain, so the licences could not be confirmed"))
+		return false
+	case problem != "":
+		first, _, _ := strings.Cut(problem, "\n")
+		fmt.Println(ui.ErrorStyle.Render("✗ flutter doctor still reports: " + first))
+		fmt.Println(ui.SubtleStyle.Render("    Run 'flutter doctor --android-licenses' to accept them through sdkmanager"))
+		return false
+	}
+	fmt.Println(ui.SuccessStyle.Render("✓ flutter doctor reports the Android licences as accepted"))
+	return true
+}
+
+// printProgress redraws a single progress line in place
+func printProgress(percent int, status string) {
+	fmt.Printf("\r\033[K%s %s",
+		ui.SimpleProgressBar(percent, 40),
+		ui.SubtleStyle.Render(status))
+}
+
+func runFlutterDoctor(inst installer.Installer) {
+	fmt.Println(ui.Header("Running Flutter Doctor"))
+
+	var report *doctor.Report
+	var output string
+	err := ui.RunTask("Running flutter doctor...", func(ctx context.Context) error {
+		var err error
+		report, output, err = runDoctor(ctx, inst)
+		return err
+	})
+	if err := reportDoctorRun(report, output, err, false); err != nil {
+		waitForEnter()
+		return
+	}
+
+	// Show the categories as a tree the user can expand and collapse
+	if _, err := tea.NewProgram(ui.NewDoctorTree(report)).Run(); err != nil {
+		fmt.Println("Error:", err)
+	}
+}
+
+// printFlutterDoctor runs flutter doctor and parses its output, printing
+// the raw output as well when verbose is set
+func printFlutterDoctor(ctx context.Context, inst installer.Installer, verbose bool) (*doctor.Report, error) {
+	fmt.Fprintln(os.Stderr, ui.Header("Running Flutter Doctor"))
+
+	report, output, err := runDoctor(ctx, inst)
+	return report, reportDoctorRun(report, output, err, verbose)
+}
+
+// runDoctor runs flutter doctor and parses its output. flutter doctor
+// may exit non-zero when it finds issues, so that only counts as a
+// failure when nothing could be parsed
+func runDoctor(ctx context.Context, inst installer.Installer) (*doctor.Report, string, error) {
+	output, err := inst.RunFlutterDoctor(ctx)
+	if ctx.Err() != nil {
+		return nil, output, ctx.Err()
+	}
+
+	report := doctor.Parse(output)
+	if len(report.Categories) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no doctor categories in output")
+		}
+		return nil, output, err
+	}
+	return report, output, nil
+}
+
+// reportDoctorRun prints why flutter doctor failed, or saves a
+// successful run to the history so it can be compared with later ones
+func reportDoctorRun(report *doctor.Report, output string, err error, verbose bool) error {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, ui.WarningStyle.Render("! flutter doctor was cancelled\n"))
+		return err
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render("✗ Failed to run flutter doctor"))
+		fmt.Fprintln(os.Stderr, ui.SubtleStyle.Render("  Error: "+err.Error()))
+		if strings.TrimSpace(output) != "" {
+			fmt.Fprintln(os.Stderr, output)
+		}
+		fmt.Fprintln(os.Stderr, ui.SubtleStyle.Render("\n  Make sure Flutter is installed and added to PATH\n"))
+		return err
+	}
+
+	if verbose {
 		fmt.Println(output)
 	}
 
+	history, err := doctor.NewHistory()
+	if err == nil {
+		err = history.Save(time.Now(), report)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.WarningStyle.Render("! Could not save doctor history: "+err.Error()))
+	}
+	return nil
+}
+
+func showDoctorChanges() {
+	printDoctorChanges()
+	waitForEnter()
+}
+
+// lastDoctorRuns returns the two most recent doctor runs from the history
+func lastDoctorRuns() (before, after doctor.Run, err error) {
+	history, err := doctor.NewHistory()
+	if err != nil {
+		return before, after, err
+	}
+	runs, err := history.Runs()
+	if err != nil {
+		return before, after, err
+	}
+	if len(runs) < 2 {
+		return before, after, fmt.Errorf("need two saved flutter doctor runs to compare, found %d", len(runs))
+	}
+	return runs[len(runs)-2], runs[len(runs)-1], nil
+}
+
+// printDoctorChanges compares the last two doctor runs category by category
+func printDoctorChanges() error {
+	fmt.Println(ui.Header("What Changed Since Last Time"))
+
+	before, after, err := lastDoctorRuns()
+	if err != nil {
+		fmt.Println(ui.WarningStyle.Render("! " + err.Error() + "\n"))
+		return err
+	}
+
+	fmt.Println(ui.SubtleStyle.Render(fmt.Sprintf("Comparing %s with %s\n",
+		before.Time.Local().Format("2006-01-02 15:04"), after.Time.Local().Format("2006-01-02 15:04"))))
+
+	changes := doctor.Diff(before.Report, after.Report)
+	if len(changes) == 0 {
+		fmt.Println(ui.SuccessStyle.Render("✓ Nothing changed\n"))
+		return nil
+	}
+
+	for _, c := range changes {
+		switch c.Kind {
+		case doctor.ChangeAdded:
+			fmt.Printf("%s %s %s\n", ui.HeaderStyle.Render("new"), ui.DoctorStatus(c.After.Status), c.Name)
+		case doctor.ChangeRemoved:
+			fmt.Printf("%s %s %s\n", ui.SubtleStyle.Render("gone"), ui.DoctorStatus(c.Before.Status), c.Name)
+		case doctor.ChangeStatus:
+			fmt.Printf("%s → %s %s\n", ui.DoctorStatus(c.Before.Status), ui.DoctorStatus(c.After.Status), c.Name)
+		default:
+			fmt.Printf("%s %s\n", ui.DoctorStatus(c.After.Status), c.Name)
+		}
+		if c.Before != nil && c.After != nil && c.Before.Summary != c.After.Summary {
+			fmt.Println(ui.SubtleStyle.Render(fmt.Sprintf("    %q → %q", c.Before.Summary, c.After.Summary)))
+		}
+
+		for _, m := range c.NewHints {
+			printDoctorHint(ui.ErrorStyle.Render("+"), m.Text)
+		}
+		for _, m := range c.ResolvedHints {
+			printDoctorHint(ui.SuccessStyle.Render("-"), m.Text)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// printDoctorHint prints a hint that may span several lines under marker
+func printDoctorHint(marker, text string) {
+	for i, line := range strings.Split(text, "\n") {
+		if i == 0 {
+			fmt.Println("    " + marker + " " + ui.SubtleStyle.Render(line))
+		} else {
+			fmt.Println("      " + ui.SubtleStyle.Render(line))
+		}
+	}
+}
+
+func manageJDKs(inst installer.Installer) {
+	fmt.Println(ui.Header("Java JDKs"))
+
+	var installs []jdk.Install
+	err := ui.RunTask("Looking for JDKs...", func(ctx context.Context) error {
+		installs = inst.FindJDKs(ctx)
+		return ctx.Err()
+	})
+	if err != nil {
+		fmt.Println(ui.WarningStyle.Render("! JDK search cancelled\n"))
+		waitForEnter()
+		return
+	}
+
+	best, ok := printJDKs(installs)
+	if !ok {
+		waitForEnter()
+		return
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s ", ui.SubtleStyle.Render(fmt.Sprintf("Use which JDK? (1-%d, Enter for the recommended one):", len(installs))))
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+
+	selected := best
+	if choice != "" {
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(installs) {
+			fmt.Println(ui.WarningStyle.Render("Invalid choice, nothing changed\n"))
+			waitForEnter()
+			return
+		}
+		selected = installs[n-1]
+	}
+	if !selected.Usable() {
+		fmt.Println(ui.ErrorStyle.Render(fmt.Sprintf("✗ Java %s cannot build Flutter apps: %s\n", selected.Info, jdkProblem(selected))))
+		waitForEnter()
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(ui.SubtleStyle.Render("Choose how to use " + selected.Home + ":"))
+	fmt.Println(ui.SubtleStyle.Render("  1. Set JAVA_HOME and run flutter config --jdk-dir"))
+	fmt.Println(ui.SubtleStyle.Render("  2. Set JAVA_HOME only"))
+	fmt.Println(ui.SubtleStyle.Render("  3. Run flutter config --jdk-dir only"))
+	fmt.Printf("\n%s ", ui.SubtleStyle.Render("Your choice (1-3):"))
+	choice, _ = reader.ReadString('\n')
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	switch strings.TrimSpace(choice) {
+	case "1", "":
+		useJDK(ctx, inst, selected, true, true)
+	case "2":
+		useJDK(ctx, inst, selected, true, false)
+	case "3":
+		useJDK(ctx, inst, selected, false, true)
+	default:
+		fmt.Println(ui.WarningStyle.Render("Invalid choice, nothing changed"))
+	}
+	fmt.Println()
 	waitForEnter()
 }
 
+// printJDKs lists the JDKs found, marking the recommended one, and
+// returns it. It reports false when none of them can build Flutter apps
+func printJDKs(installs []jdk.Install) (jdk.Install, bool) {
+	if len(installs) == 0 {
+		fmt.Println(ui.WarningStyle.Render("! No Java installations found"))
+		fmt.Println(ui.SubtleStyle.Render(fmt.Sprintf("    Install a JDK %d or newer, e.g. Eclipse Temurin from https://adoptium.net/", jdk.MinimumMajor)))
+		fmt.Println()
+		return jdk.Install{}, false
+	}
+
+	best, ok := jdk.Recommend(installs)
+	for i, install := range installs {
+		status, text := "success", ""
+		if !install.Usable() {
+			status, text = "error", " - "+jdkProblem(install)
+		}
+		if ok && install == best {
+			text = " (recommended)"
+		}
+		fmt.Printf("%2d. %s %s\n", i+1,
+			ui.StatusIndicator(status, install.Info.String()),
+			ui.SubtleStyle.Render("["+install.Source+"]"+text))
+		fmt.Println(ui.SubtleStyle.Render("      " + install.Home))
+	}
+	fmt.Println()
+
+	if !ok {
+		fmt.Println(ui.WarningStyle.Render("! None of these JDKs can build Flutter apps"))
+		fmt.Println(ui.SubtleStyle.Render(fmt.Sprintf("    Install a JDK %d or newer, e.g. Eclipse Temurin from https://adoptium.net/", jdk.MinimumMajor)))
+		fmt.Println()
+	}
+	return best, ok
+}
+
+// jdkProblem explains why install is not usable
+func jdkProblem(install jdk.Install) string {
+	if !install.Info.Supported() {
+		return fmt.Sprintf("too old, Flutter needs JDK %d or newer", jdk.MinimumMajor)
+	}
+	return "a runtime (JRE) without javac"
+}
+
+// useJDK makes install the JDK for new terminals (JAVA_HOME) and for
+// flutter (flutter config --jdk-dir), and reports whether both worked
+func useJDK(ctx context.Context, inst installer.Installer, install jdk.Install, javaHome, flutter bool) bool {
+	ok := true
+	if javaHome {
+		if msg, err := inst.SetJavaHome(install.Home); err != nil {
+			fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+			ok = false
+		} else {
+			fmt.Println(ui.SuccessStyle.Render("✓ " + msg))
+			fmt.Println(ui.SubtleStyle.Render("  Restart your terminal for JAVA_HOME to take effect"))
+		}
+	}
+	if flutter {
+		fmt.Println(ui.SubtleStyle.Render("Running flutter config --jdk-dir=" + install.Home))
+		if err := inst.SetFlutterJDK(ctx, install.Home); err != nil {
+			fmt.Println(ui.ErrorStyle.Render("✗ " + err.Error()))
+			ok = false
+		} else {
+			fmt.Println(ui.SuccessStyle.Render("✓ Flutter now builds with " + install.Home))
+		}
+	}
+	return ok
+}
+
 func showVersionInfo() {
+	printVersionInfo()
+	waitForEnter()
+}
+
+func printVersionInfo() {
 	fmt.Println(ui.Header("Version Information"))
 
 	info := version.BuildInfo()
@@ -405,8 +1587,6 @@ func showVersionInfo() {
 	fmt.Println(ui.SubtleStyle.Render("GitHub: https://github.com/LahiruHW/flutter-takeoff"))
 	fmt.Println(ui.SubtleStyle.Render("Report issues: https://github.com/LahiruHW/flutter-takeoff/issues"))
 	fmt.Println()
-
-	waitForEnter()
 }
 
 func waitForEnter() {
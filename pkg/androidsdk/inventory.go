@@ -0,0 +1,327 @@
+package androidsdk
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Component kinds, the first segment of an sdkmanager package path
+const (
+	KindPlatformTools = "platform-tools"
+	KindCmdlineTools  = "cmdline-tools"
+	KindBuildTools    = "build-tools"
+	KindPlatform      = "platforms"
+	KindNDK           = "ndk"
+	KindEmulator      = "emulator"
+	KindSystemImage   = "system-images"
+)
+
+// Component is a package installed in the SDK
+type Component struct {
+	// Path is the sdkmanager package path, e.g. "build-tools;34.0.0"
+	Path        string `json:"path"`
+	Revision    string `json:"revision"`
+	DisplayName string `json:"display_name"`
+	// Dir is where the component is installed
+	Dir string `json:"dir"`
+}
+
+// Kind returns the component kind, e.g. KindBuildTools
+func (c Component) Kind() string {
+	kind, _, _ := strings.Cut(c.Path, ";")
+	if kind == "ndk-bundle" {
+		return KindNDK
+	}
+	return kind
+}
+
+// Inventory lists the components installed in an SDK root
+type Inventory struct {
+	Root       string      `json:"root"`
+	Components []Component `json:"components"`
+}
+
+// localPackage is the part of a component's package.xml the inventory
+// reads
+type localPackage struct {
+	Package struct {
+		Path        string   `xml:"path,attr"`
+		Revision    Revision `xml:"revision"`
+		DisplayName string   `xml:"display-name"`
+	} `xml:"localPackage"`
+}
+
+// ReadInventory lists the components installed in the SDK at root. Each
+// component is described by the package.xml sdkmanager writes, or the
+// source.properties file of older installs; folders with neither are
+// incomplete installs and are skipped
+func ReadInventory(root string) (*Inventory, error) {
+	if info, err := os.Stat(root); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	// Each pattern matches component folders; the segments after the
+	// first one name the package, e.g. build-tools/34.0.0
+	patterns := []string{
+		"platform-tools",
+		"emulator",
+		"ndk-bundle",
+		filepath.Join("cmdline-tools", "*"),
+		filepath.Join("build-tools", "*"),
+		filepath.Join("platforms", "*"),
+		filepath.Join("ndk", "*"),
+		filepath.Join("system-images", "*", "*", "*"),
+	}
+
+	inv := &Inventory{Root: root, Components: []Component{}}
+	for _, pattern := range patterns {
+		dirs, _ := filepath.Glob(filepath.Join(root, pattern))
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			rel, _ := filepath.Rel(root, dir)
+			c, err := readComponent(dir, strings.ReplaceAll(filepath.ToSlash(rel), "/", ";"))
+			if err == nil {
+				inv.Components = append(inv.Components, c)
+			}
+		}
+	}
+	return inv, nil
+}
+
+// readComponent describes the component in dir, using path when its
+// metadata does not name the package
+func readComponent(dir, path string) (Component, error) {
+	c := Component{Path: path, Dir: dir}
+
+	if f, err := os.Open(filepath.Join(dir, "package.xml")); err == nil {
+		defer f.Close()
+		var pkg localPackage
+		if err := xml.NewDecoder(f).Decode(&pkg); err != nil {
+			return c, fmt.Errorf("failed to parse %s: %w", f.Name(), err)
+		}
+		if pkg.Package.Path != "" {
+			c.Path = pkg.Package.Path
+		}
+		c.Revision = pkg.Package.Revision.String()
+		c.DisplayName = pkg.Package.DisplayName
+		return c, nil
+	}
+
+	props, err := readProperties(filepath.Join(dir, "source.properties"))
+	if err != nil {
+		return c, err
+	}
+	c.Revision = props["Pkg.Revision"]
+	c.DisplayName = props["Pkg.Desc"]
+	if c.Revision == "" {
+		return c, errors.New(dir + " has no Pkg.Revision")
+	}
+	return c, nil
+}
+
+// readProperties reads the key=value lines of a Java properties file
+func readProperties(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return props, scanner.Err()
+}
+
+// Kind returns the installed components of a kind, in path order
+func (inv *Inventory) Kind(kind string) []Component {
+	var out []Component
+	for _, c := range inv.Components {
+		if c.Kind() == kind {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Requirements are the SDK components a Flutter release builds with
+type Requirements struct {
+	// CompileSDK is the Android API level of the platform Flutter compiles
+	// apps against by default
+	CompileSDK int
+	// BuildTools is the oldest build-tools version flutter doctor accepts
+	BuildTools string
+	// NDK is the NDK version Flutter's Gradle plugin pins, needed by
+	// plugins with native code
+	NDK string
+	// SystemImageABI is the ABI of the system image suggested when none is
+	// installed: "x86_64", or "arm64-v8a" on Apple Silicon. Defaults to x86_64
+	SystemImageABI string
+}
+
+// requirements maps the first Flutter release of each line to the
+// defaults of its Gradle plugin (compileSdkVersion, ndkVersion) and the
+// build-tools minimum of its doctor, newest first
+var requirements = []struct {
+	flutter string
+	Requirements
+}{
+	{"3.29", Requirements{CompileSDK: 35, BuildTools: "34.0.0", NDK: "27.0.12077973"}},
+	{"3.27", Requirements{CompileSDK: 35, BuildTools: "34.0.0", NDK: "26.5.11579264"}},
+	{"3.22", Requirements{CompileSDK: 34, BuildTools: "34.0.0", NDK: "23.1.7779620"}},
+	{"3.16", Requirements{CompileSDK: 34, BuildTools: "33.0.0", NDK: "23.1.7779620"}},
+	{"0", Requirements{CompileSDK: 33, BuildTools: "30.0.3", NDK: "23.1.7779620"}},
+}
+
+// RequirementsFor returns what flutterVersion needs. "latest", "" and
+// versions that cannot be parsed get the newest requirements; a release
+// line such as "3.22.x" gets those of its first release
+func RequirementsFor(flutterVersion string) Requirements {
+	v := strings.TrimSuffix(strings.TrimSpace(flutterVersion), ".x")
+	if v == "" || v == "latest" || !isDotted(v) {
+		return requirements[0].Requirements
+	}
+	for _, r := range requirements {
+		if CompareRevisions(v, r.flutter) >= 0 {
+			return r.Requirements
+		}
+	}
+	return requirements[len(requirements)-1].Requirements
+}
+
+// Issue is a component the SDK is missing or has too old a version of
+type Issue struct {
+	// Package is the sdkmanager package that fixes the issue
+	Package string `json:"package"`
+	Message string `json:"message"`
+	// Required is false for components only some apps need, such as the
+	// NDK, or that are only needed to run an emulator
+	Required bool `json:"required"`
+}
+
+// Check compares the inventory with req
+func (inv *Inventory) Check(req Requirements) []Issue {
+	issues := []Issue{}
+
+	if len(inv.Kind(KindPlatformTools)) == 0 {
+		issues = append(issues, Issue{Package: "platform-tools", Message: "platform-tools (adb) is not installed", Required: true})
+	}
+	if len(inv.Kind(KindCmdlineTools)) == 0 {
+		issues = append(issues, Issue{Package: "cmdline-tools;latest", Message: "cmdline-tools (sdkmanager) is not installed", Required: true})
+	}
+
+	newestAPI := 0
+	for _, c := range inv.Kind(KindPlatform) {
+		if api := apiLevel(c.Path); api > newestAPI {
+			newestAPI = api
+		}
+	}
+	platform := fmt.Sprintf("platforms;android-%d", req.CompileSDK)
+	switch {
+	case newestAPI == 0:
+		issues = append(issues, Issue{Package: platform, Message: fmt.Sprintf("No Android platform is installed; Flutter compiles against android-%d", req.CompileSDK), Required: true})
+	case newestAPI < req.CompileSDK:
+		issues = append(issues, Issue{Package: platform, Message: fmt.Sprintf("Newest platform is android-%d; Flutter compiles against android-%d", newestAPI, req.CompileSDK), Required: true})
+	}
+
+	newestBuildTools := ""
+	for _, c := range inv.Kind(KindBuildTools) {
+		if newestBuildTools == "" || CompareRevisions(c.Revision, newestBuildTools) > 0 {
+			newestBuildTools = c.Revision
+		}
+	}
+	buildTools := "build-tools;" + req.BuildTools
+	switch {
+	case newestBuildTools == "":
+		issues = append(issues, Issue{Package: buildTools, Message: "build-tools are not installed; Flutter needs " + req.BuildTools + " or newer", Required: true})
+	case CompareRevisions(newestBuildTools, req.BuildTools) < 0:
+		issues = append(issues, Issue{Package: buildTools, Message: "build-tools " + newestBuildTools + " is too old; Flutter needs " + req.BuildTools + " or newer", Required: true})
+	}
+
+	if req.NDK != "" && !inv.hasNDK(req.NDK) {
+		issues = append(issues, Issue{Package: "ndk;" + req.NDK, Message: "NDK " + req.NDK + " is not installed; plugins with native code need it"})
+	}
+	if len(inv.Kind(KindEmulator)) == 0 {
+		issues = append(issues, Issue{Package: "emulator", Message: "The emulator is not installed"})
+	}
+	if len(inv.Kind(KindSystemImage)) == 0 {
+		abi := req.SystemImageABI
+		if abi == "" {
+			abi = "x86_64"
+		}
+		issues = append(issues, Issue{Package: fmt.Sprintf("system-images;android-%d;google_apis;%s", req.CompileSDK, abi), Message: "No system images are installed, so no emulator can be created"})
+	}
+	return issues
+}
+
+func (inv *Inventory) hasNDK(version string) bool {
+	for _, c := range inv.Kind(KindNDK) {
+		if c.Revision == version {
+			return true
+		}
+	}
+	return false
+}
+
+// apiLevel returns the API level of "platforms;android-34", or 0 for
+// preview platforms such as android-VanillaIceCream
+func apiLevel(path string) int {
+	_, name, _ := strings.Cut(path, ";android-")
+	n, _ := strconv.Atoi(name)
+	return n
+}
+
+// CompareRevisions compares dotted revisions such as "34.0.0" and "30.0.3"
+// numerically, returning -1, 0 or 1. Missing parts count as 0 and
+// suffixes such as "-rc1" are ignored
+func CompareRevisions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		na, nb := revisionPart(pa, i), revisionPart(pb, i)
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func revisionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	digits := parts[i]
+	if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		digits = digits[:end]
+	}
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+func isDotted(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package androidsdk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// packageXML is what sdkmanager writes next to each component it installs
+const packageXML = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<ns2:repository xmlns:ns2="http://schemas.android.com/repository/android/common/02">
+<license id="android-sdk-license" type="text">Terms and Conditions</license>
+<localPackage path="%s" obsolete="false">
+<revision>%s</revision>
+<display-name>%s</display-name>
+<uses-license ref="android-sdk-license"/>
+</localPackage>
+</ns2:repository>`
+
+// writeSDKFile creates root/rel with content
+func writeSDKFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writePackage(t *testing.T, root, dir, path, revision, name string) {
+	t.Helper()
+	writeSDKFile(t, root, dir+"/package.xml", fmt.Sprintf(packageXML, path, revision, name))
+}
+
+func TestReadInventory(t *testing.T) {
+	root := t.TempDir()
+	writePackage(t, root, "platform-tools", "platform-tools", "<major>35</major><minor>0</minor><micro>1</micro>", "Android SDK Platform-Tools")
+	writePackage(t, root, "cmdline-tools/latest", "cmdline-tools;latest", "<major>13</major><minor>0</minor>", "Android SDK Command-line Tools (latest)")
+	writePackage(t, root, "build-tools/30.0.3", "build-tools;30.0.3", "<major>30</major><minor>0</minor><micro>3</micro>", "Android SDK Build-Tools 30.0.3")
+	writePackage(t, root, "platforms/android-33", "platforms;android-33", "<major>3</major>", "Android SDK Platform 33")
+	writePackage(t, root, "system-images/android-33/google_apis/x86_64", "system-images;android-33;google_apis;x86_64",
+		"<major>17</major>", "Google APIs Intel x86_64 Atom System Image")
+	// An NDK installed by an older SDK manager only has source.properties
+	writeSDKFile(t, root, "ndk/23.1.7779620/source.properties", "Pkg.Desc = Android NDK\nPkg.Revision = 23.1.7779620\n")
+	// A download that never finished
+	if err := os.MkdirAll(filepath.Join(root, "build-tools", "34.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := ReadInventory(root)
+	if err != nil {
+		t.Fatalf("ReadInventory() error = %v", err)
+	}
+
+	var got []string
+	for _, c := range inv.Components {
+		got = append(got, c.Path+" "+c.Revision)
+	}
+	want := []string{
+		"platform-tools 35.0.1",
+		"cmdline-tools;latest 13.0.0",
+		"build-tools;30.0.3 30.0.3",
+		"platforms;android-33 3.0.0",
+		"ndk;23.1.7779620 23.1.7779620",
+		"system-images;android-33;google_apis;x86_64 17.0.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("components =\n%q\nwant\n%q", got, want)
+	}
+	if ndk := inv.Kind(KindNDK); len(ndk) != 1 || ndk[0].DisplayName != "Android NDK" {
+		t.Errorf("Kind(ndk) = %+v", ndk)
+	}
+
+	// Flutter 3.24 compiles against android-34 and needs build-tools 34
+	var packages []string
+	required := 0
+	for _, issue := range inv.Check(RequirementsFor("3.24.5")) {
+		packages = append(packages, issue.Package)
+		if issue.Required {
+			required++
+		}
+	}
+	wantPackages := []string{"platforms;android-34", "build-tools;34.0.0", "emulator"}
+	if !reflect.DeepEqual(packages, wantPackages) || required != 2 {
+		t.Errorf("Check() packages = %q (%d required), want %q (2 required)", packages, required, wantPackages)
+	}
+}
+
+func TestCheckEmptySDK(t *testing.T) {
+	inv, err := ReadInventory(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := RequirementsFor("latest")
+	req.SystemImageABI = "arm64-v8a"
+	issues := inv.Check(req)
+	if len(issues) != 7 {
+		t.Fatalf("Check() = %d issues, want 7: %+v", len(issues), issues)
+	}
+	if got := issues[len(issues)-1].Package; got != "system-images;android-35;google_apis;arm64-v8a" {
+		t.Errorf("system image package = %q", got)
+	}
+}
+
+func TestReadInventoryMissingRoot(t *testing.T) {
+	if _, err := ReadInventory(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ReadInventory() of a missing root should fail")
+	}
+}
+
+func TestRequirementsFor(t *testing.T) {
+	tests := []struct {
+		version        string
+		wantCompileSDK int
+		wantBuildTools string
+	}{
+		{"latest", 35, "34.0.0"},
+		{"", 35, "34.0.0"},
+		{"3.22.x", 34, "34.0.0"},
+		{"3.24.5", 34, "34.0.0"},
+		{"3.27.0", 35, "34.0.0"},
+		{"3.19.6", 34, "33.0.0"},
+		{"3.10.0", 33, "30.0.3"},
+	}
+	for _, tt := range tests {
+		got := RequirementsFor(tt.version)
+		if got.CompileSDK != tt.wantCompileSDK || got.BuildTools != tt.wantBuildTools {
+			t.Errorf("RequirementsFor(%q) = %+v, want android-%d, build-tools %s", tt.version, got, tt.wantCompileSDK, tt.wantBuildTools)
+		}
+	}
+}
+
+func TestCompareRevisions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"34.0.0", "30.0.3", 1},
+		{"30.0.3", "34.0.0", -1},
+		{"34", "34.0.0", 0},
+		{"35.0.0-rc1", "35.0.0", 0},
+		{"3.9", "3.22", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareRevisions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareRevisions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
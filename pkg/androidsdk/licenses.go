@@ -0,0 +1,87 @@
+package androidsdk
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// License is a licence text from the repository manifest. Packages refer
+// to it by ID, e.g. "android-sdk-license"
+type License struct {
+	ID   string `xml:"id,attr" json:"id"`
+	Text string `xml:",chardata" json:"text"`
+}
+
+// Hash returns the SHA-1 of the licence text, which sdkmanager writes to
+// <sdk>/licenses/<id> when the licence is accepted. Like sdkmanager, it
+// hashes the text without surrounding whitespace, so a licence counts as
+// accepted only for the revision of the text that was read
+func (l License) Hash() string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(l.Text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// LicenseTexts returns the licences in the manifest, in manifest order,
+// with surrounding whitespace trimmed
+func (r *Repository) LicenseTexts() []License {
+	out := make([]License, len(r.Licenses))
+	for i, l := range r.Licenses {
+		out[i] = License{ID: l.ID, Text: strings.TrimSpace(l.Text)}
+	}
+	return out
+}
+
+// LicenseAccepted reports whether <root>/licenses/<id> lists the hash of
+// the licence's current text
+func LicenseAccepted(root string, l License) bool {
+	return slices.Contains(readLicenseFile(filepath.Join(root, "licenses", l.ID)), l.Hash())
+}
+
+// AcceptLicenses records the licences as accepted the way sdkmanager
+// --licenses does, by adding the hash of each text to the files in
+// <root>/licenses. Hashes already in a file are kept
+func AcceptLicenses(root string, licenses []License) error {
+	dir := filepath.Join(root, "licenses")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	for _, l := range licenses {
+		if strings.TrimSpace(l.Text) == "" {
+			return fmt.Errorf("Android SDK licence %q has no text to accept", l.ID)
+		}
+
+		path := filepath.Join(dir, l.ID)
+		hashes := readLicenseFile(path)
+		if hash := l.Hash(); !slices.Contains(hashes, hash) {
+			hashes = append(hashes, hash)
+		}
+
+		// sdkmanager starts the file with a blank line
+		if err := os.WriteFile(path, []byte("\n"+strings.Join(hashes, "\n")), 0o644); err != nil {
+			return fmt.Errorf("failed to accept %s: %w", l.ID, err)
+		}
+	}
+	return nil
+}
+
+// readLicenseFile returns the hashes in a licence file, or nil when it
+// does not exist
+func readLicenseFile(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var hashes []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			hashes = append(hashes, line)
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,92 @@
+package androidsdk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLicenseTexts(t *testing.T) {
+	licenses := loadRepository(t).LicenseTexts()
+
+	if len(licenses) != 3 {
+		t.Fatalf("LicenseTexts() = %+v, want the SDK, preview and vendor licences", licenses)
+	}
+	if licenses[0].ID != "android-sdk-license" || licenses[0].Text != "Terms and Conditions" {
+		t.Errorf("licenses[0] = %+v", licenses[0])
+	}
+	if want := "To get started with the Android SDK Preview"; !strings.HasPrefix(licenses[1].Text, want) || strings.HasSuffix(licenses[1].Text, "\t") {
+		t.Errorf("licenses[1].Text = %q, want it trimmed and starting with %q", licenses[1].Text, want)
+	}
+}
+
+func TestLicenseHash(t *testing.T) {
+	// sdkmanager hashes the text without surrounding whitespace
+	for _, text := range []string{"Terms and Conditions", "\n\tTerms and Conditions\n"} {
+		if got := (License{Text: text}).Hash(); got != "894031ed8d341b5ecab5e23002585055f0b7ee4d" {
+			t.Errorf("Hash() of %q = %s", text, got)
+		}
+	}
+}
+
+func TestAcceptLicenses(t *testing.T) {
+	root := t.TempDir()
+	licenses := loadRepository(t).LicenseTexts()
+	sdk := licenses[0]
+	if LicenseAccepted(root, sdk) {
+		t.Fatal("LicenseAccepted() = true before accepting")
+	}
+
+	// A hash accepted earlier by sdkmanager, for an older text, is kept
+	if err := os.MkdirAll(filepath.Join(root, "licenses"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "licenses", sdk.ID)
+	if err := os.WriteFile(path, []byte("\n0123456789abcdef0123456789abcdef01234567"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if LicenseAccepted(root, sdk) {
+		t.Fatal("LicenseAccepted() = true for a hash of another text")
+	}
+
+	if err := AcceptLicenses(root, licenses[:2]); err != nil {
+		t.Fatalf("AcceptLicenses() error = %v", err)
+	}
+	for _, l := range licenses[:2] {
+		if !LicenseAccepted(root, l) {
+			t.Errorf("LicenseAccepted(%q) = false after accepting", l.ID)
+		}
+	}
+	// Only the licences asked for are accepted
+	if LicenseAccepted(root, licenses[2]) {
+		t.Errorf("LicenseAccepted(%q) = true without accepting it", licenses[2].ID)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\n0123456789abcdef0123456789abcdef01234567\n894031ed8d341b5ecab5e23002585055f0b7ee4d"
+	if string(data) != want {
+		t.Errorf("licence file = %q, want %q", data, want)
+	}
+
+	// Accepting again does not duplicate the hash
+	if err := AcceptLicenses(root, licenses[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(path); string(again) != want {
+		t.Errorf("licence file after accepting twice = %q", again)
+	}
+
+	// A changed text is a new revision that has not been accepted
+	changed := License{ID: sdk.ID, Text: sdk.Text + " (revised)"}
+	if LicenseAccepted(root, changed) {
+		t.Error("LicenseAccepted() = true for a revised text")
+	}
+
+	if err := AcceptLicenses(root, []License{{ID: "empty-license"}}); err == nil {
+		t.Error("AcceptLicenses() accepted a licence without a text")
+	}
+}
@@ -0,0 +1,142 @@
+package androidsdk
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultRepositoryURL is where sdkmanager downloads packages from
+const DefaultRepositoryURL = "https://dl.google.com/android/repository/"
+
+// RepositoryManifest is the file listing the packages of the repository
+const RepositoryManifest = "repository2-3.xml"
+
+// CmdlineTools is the package path of the latest command-line tools,
+// which include sdkmanager
+const CmdlineTools = "cmdline-tools;latest"
+
+// Repository is the parsed repository manifest
+type Repository struct {
+	Channels []Channel       `xml:"channel"`
+	Packages []RemotePackage `xml:"remotePackage"`
+	Licenses []License       `xml:"license"`
+}
+
+// Channel names a release channel; packages refer to it by ID
+type Channel struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:",chardata"`
+}
+
+// RemotePackage is one package in the repository, e.g.
+// "platforms;android-34". A package can be listed once per channel
+type RemotePackage struct {
+	Path        string   `xml:"path,attr"`
+	DisplayName string   `xml:"display-name"`
+	Revision    Revision `xml:"revision"`
+	ChannelRef  struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"channelRef"`
+	Archives []Archive `xml:"archives>archive"`
+}
+
+// Revision is a package version such as 34.0.0, or 35.0.0-rc1 for a
+// preview
+type Revision struct {
+	Major   int `xml:"major"`
+	Minor   int `xml:"minor"`
+	Micro   int `xml:"micro"`
+	Preview int `xml:"preview"`
+}
+
+func (r Revision) String() string {
+	s := strconv.Itoa(r.Major) + "." + strconv.Itoa(r.Minor) + "." + strconv.Itoa(r.Micro)
+	if r.Preview > 0 {
+		s += "-rc" + strconv.Itoa(r.Preview)
+	}
+	return s
+}
+
+// Archive is the download of a package for one host
+type Archive struct {
+	// HostOS is "windows", "macosx" or "linux", or empty when the archive
+	// works everywhere
+	HostOS   string `xml:"host-os"`
+	Size     int64  `xml:"complete>size"`
+	Checksum struct {
+		// Type is "sha1" in every current manifest; older ones leave it out
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"complete>checksum"`
+	// URL is relative to the repository URL
+	URL string `xml:"complete>url"`
+}
+
+// SHA1 returns the archive's SHA-1 checksum, or "" when the manifest
+// gives another kind
+func (a Archive) SHA1() string {
+	if a.Checksum.Type != "" && a.Checksum.Type != "sha1" {
+		return ""
+	}
+	return strings.TrimSpace(a.Checksum.Value)
+}
+
+// HostOS returns the host-os name the repository uses for goos
+func HostOS(goos string) string {
+	if goos == "darwin" {
+		return "macosx"
+	}
+	return goos
+}
+
+// Fetch downloads and parses the repository manifest at url
+func Fetch(client *http.Client, url string) (*Repository, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Android repository manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch Android repository manifest %s: %s", url, resp.Status)
+	}
+
+	return Parse(resp.Body)
+}
+
+// Parse decodes a repository manifest
+func Parse(r io.Reader) (*Repository, error) {
+	var repo Repository
+	if err := xml.NewDecoder(r).Decode(&repo); err != nil {
+		return nil, fmt.Errorf("failed to parse Android repository manifest: %w", err)
+	}
+	return &repo, nil
+}
+
+// Archive returns the stable-channel archive of the package at path for
+// hostOS (see HostOS)
+func (r *Repository) Archive(path, hostOS string) (*Archive, error) {
+	stable := "channel-0"
+	for _, c := range r.Channels {
+		if strings.TrimSpace(c.Name) == "stable" {
+			stable = c.ID
+		}
+	}
+
+	for _, p := range r.Packages {
+		if p.Path != path || (p.ChannelRef.Ref != "" && p.ChannelRef.Ref != stable) {
+			continue
+		}
+		for i, a := range p.Archives {
+			if a.HostOS == "" || a.HostOS == hostOS {
+				return &p.Archives[i], nil
+			}
+		}
+		return nil, fmt.Errorf("package %s has no archive for %s", path, hostOS)
+	}
+	return nil, fmt.Errorf("package %s not found in the Android repository", path)
+}
@@ -0,0 +1,83 @@
+package androidsdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func loadRepository(t *testing.T) *Repository {
+	t.Helper()
+	f, err := os.Open("testdata/repository2-3.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	repo, err := Parse(f)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return repo
+}
+
+func TestRepositoryArchive(t *testing.T) {
+	repo := loadRepository(t)
+
+	if len(repo.Packages) != 3 {
+		t.Fatalf("got %d packages, want 3", len(repo.Packages))
+	}
+	if got := repo.Packages[1].Revision.String(); got != "13.0.0" {
+		t.Errorf("revision = %q, want 13.0.0", got)
+	}
+
+	tests := []struct {
+		path, hostOS string
+		wantURL      string
+		wantSHA1     string
+		wantErr      bool
+	}{
+		// The canary build listed first is skipped for the stable one
+		{CmdlineTools, "linux", "commandlinetools-linux-11479570_latest.zip", "f8eb5ad96dd8d2c7ae3d3fac1e3cb5d4d6dcd30e", false},
+		{CmdlineTools, HostOS("darwin"), "commandlinetools-mac-11479570_latest.zip", "cd9ea7d5d79b4a3d7a2b0f3f0d86b4b3c1e4e82e", false},
+		{CmdlineTools, HostOS("windows"), "commandlinetools-win-11479570_latest.zip", "3b6a64bd3d9b9d6d82b0b0e4d5d5e9b1d1b2a2c4", false},
+		// Checksums without a type are SHA-1
+		{"platform-tools", "linux", "platform-tools_r35.0.2-linux.zip", "3b4e8c7c3f0d4d16c5e1d3e6b1b4a0c5c0f8f8b1", false},
+		{"platform-tools", "windows", "", "", true},
+		{"emulator", "linux", "", "", true},
+	}
+	for _, tt := range tests {
+		a, err := repo.Archive(tt.path, tt.hostOS)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Archive(%q, %q) = %+v, want an error", tt.path, tt.hostOS, a)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Archive(%q, %q) error = %v", tt.path, tt.hostOS, err)
+			continue
+		}
+		if a.URL != tt.wantURL || a.SHA1() != tt.wantSHA1 {
+			t.Errorf("Archive(%q, %q) = %s (sha1 %s), want %s (sha1 %s)", tt.path, tt.hostOS, a.URL, a.SHA1(), tt.wantURL, tt.wantSHA1)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+
+	repo, err := Fetch(srv.Client(), srv.URL+"/"+RepositoryManifest)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(repo.Packages) != 3 {
+		t.Errorf("got %d packages, want 3", len(repo.Packages))
+	}
+
+	if _, err := Fetch(srv.Client(), srv.URL+"/missing.xml"); err == nil {
+		t.Error("Fetch() of a missing manifest should fail")
+	}
+}
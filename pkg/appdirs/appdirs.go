@@ -0,0 +1,52 @@
+package appdirs
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Name is the folder created under the user's cache and config directories
+const Name = "flutter-takeoff"
+
+// CacheDir returns the directory for files that can be re-downloaded,
+// such as partially downloaded SDK archives
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, Name), nil
+}
+
+// DataDir returns the directory for files the tool creates and keeps,
+// such as the flutter doctor history: %LOCALAPPDATA% on Windows,
+// ~/Library/Application Support on macOS and $XDG_DATA_HOME (default
+// ~/.local/share) elsewhere
+func DataDir() (string, error) {
+	var dir string
+	switch runtime.GOOS {
+	case "windows":
+		// os.UserCacheDir resolves to %LOCALAPPDATA% on Windows
+		var err error
+		if dir, err = os.UserCacheDir(); err != nil {
+			return "", err
+		}
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, "Library", "Application Support")
+	default:
+		dir = os.Getenv("XDG_DATA_HOME")
+		if dir == "" || !filepath.IsAbs(dir) {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, ".local", "share")
+		}
+	}
+	return filepath.Join(dir, Name), nil
+}
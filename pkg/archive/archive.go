@@ -0,0 +1,331 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// ProgressFunc receives the extraction percentage and a status line
+type ProgressFunc func(percent int, status string)
+
+// Extractor unpacks SDK archives. Files are extracted into a temporary
+// sibling of the destination, which is renamed into place only once
+// every entry has been written
+type Extractor struct {
+	// Overwrite allows a non-empty destination to be replaced
+	Overwrite bool
+}
+
+// New creates an Extractor that refuses to replace non-empty directories
+func New() *Extractor {
+	return &Extractor{}
+}
+
+// Extract unpacks a .zip, .tar.xz or .tar archive into dest. When the
+// archive holds a single top-level folder, as Flutter archives do, its
+// contents become dest. Cancelling ctx stops after the current entry and
+// leaves dest untouched
+func (e *Extractor) Extract(ctx context.Context, src, dest string, progress ProgressFunc) error {
+	if progress == nil {
+		progress = func(int, string) {}
+	}
+
+	if !e.Overwrite && !isEmptyDir(dest) {
+		return fmt.Errorf("%s already exists and is not empty", dest)
+	}
+
+	parent := filepath.Dir(dest)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", parent, err)
+	}
+	tmp, err := os.MkdirTemp(parent, "."+filepath.Base(dest)+"-extract-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	name := strings.ToLower(src)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		err = extractZip(ctx, src, tmp, progress)
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".tar"):
+		err = extractTar(ctx, src, tmp, progress)
+	default:
+		err = fmt.Errorf("unsupported archive format: %s", filepath.Base(src))
+	}
+	if err != nil {
+		return err
+	}
+
+	return moveIntoPlace(tmp, dest)
+}
+
+// extractZip unpacks a zip archive, reporting progress per entry
+func extractZip(ctx context.Context, src, root string, progress ProgressFunc) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(src), err)
+	}
+	defer r.Close()
+
+	for i, f := range r.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		progress(i*100/len(r.File), "Extracting "+f.Name)
+
+		target, err := safeJoin(root, f.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(target, 0755)
+		case mode&os.ModeSymlink != 0:
+			err = writeZipSymlink(f, root, target)
+		default:
+			err = writeZipFile(f, target, mode.Perm())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+	}
+
+	progress(100, fmt.Sprintf("Extracted %d files", len(r.File)))
+	return nil
+}
+
+func writeZipFile(f *zip.File, target string, perm os.FileMode) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return writeFile(target, rc, perm)
+}
+
+func writeZipSymlink(f *zip.File, root, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// A zip symlink stores its target as the file contents
+	link, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return err
+	}
+	return makeSymlink(root, string(link), target)
+}
+
+// extractTar unpacks a tar archive, decompressing .tar.xz on the fly.
+// The entry count is unknown up front, so progress follows the
+// compressed bytes read
+func extractTar(ctx context.Context, src, root string, progress ProgressFunc) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(src), err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	counter := &countingReader{r: file}
+
+	var stream io.Reader = counter
+	if strings.HasSuffix(strings.ToLower(src), ".xz") {
+		if stream, err = xz.NewReader(counter); err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(src), err)
+		}
+	}
+
+	tr := tar.NewReader(stream)
+	count := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(src), err)
+		}
+
+		percent := 0
+		if info.Size() > 0 {
+			percent = int(counter.n * 100 / info.Size())
+		}
+		progress(percent, "Extracting "+hdr.Name)
+
+		target, err := safeJoin(root, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeFile(target, tr, hdr.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			err = makeSymlink(root, hdr.Linkname, target)
+		case tar.TypeLink:
+			// safeJoin also refuses a source that is itself a symlink,
+			// whose relative target would mean something else at target
+			var source string
+			if source, err = safeJoin(root, hdr.Linkname); err == nil {
+				err = os.Link(source, target)
+			}
+		default:
+			// Devices, fifos and pax metadata have no place in an SDK
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", hdr.Name, err)
+		}
+		count++
+	}
+
+	progress(100, fmt.Sprintf("Extracted %d files", count))
+	return nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// safeJoin resolves an archive entry name inside root, rejecting absolute
+// paths and "../" entries that would escape it. Entries at or below a
+// symlink extracted earlier are rejected too: the lexical check cannot
+// tell where writing through the link would end up
+func safeJoin(root, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" || clean == ".." ||
+		strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q escapes the destination", name)
+	}
+
+	path := root
+	for _, part := range strings.Split(clean, string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+		if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("archive entry %q goes through the symlink %s", name, strings.TrimPrefix(path, root+string(filepath.Separator)))
+		}
+	}
+	return filepath.Join(root, clean), nil
+}
+
+// makeSymlink creates target pointing at link, which must resolve inside
+// root so later entries cannot be written through it
+func makeSymlink(root, link, target string) error {
+	if filepath.IsAbs(link) {
+		return fmt.Errorf("symlink %s points outside the archive: %s", target, link)
+	}
+	// ".." may only lead the link. After a symlink it climbs from wherever
+	// that link points, which the lexical check below cannot see
+	climbing := true
+	for _, part := range strings.Split(filepath.ToSlash(link), "/") {
+		if part != ".." {
+			if part != "" && part != "." {
+				climbing = false
+			}
+			continue
+		}
+		if !climbing {
+			return fmt.Errorf("symlink %s has \"..\" after a folder name: %s", target, link)
+		}
+	}
+	rel, err := filepath.Rel(root, filepath.Join(filepath.Dir(target), link))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("symlink %s points outside the archive: %s", target, link)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
+
+func writeFile(target string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	// Keep the executable bits but never create unreadable files
+	perm |= 0600
+	if runtime.GOOS == "windows" {
+		perm = 0644
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// moveIntoPlace renames the extracted tree to dest, unwrapping a single
+// top-level folder and replacing whatever was at dest before
+func moveIntoPlace(tmp, dest string) error {
+	src := tmp
+	if entries, err := os.ReadDir(tmp); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		src = filepath.Join(tmp, entries[0].Name())
+	}
+
+	// Keep the old tree until the new one is in place
+	var old string
+	if _, err := os.Lstat(dest); err == nil {
+		old = tmp + "-old"
+		if err := os.Rename(dest, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", dest, err)
+		}
+	}
+
+	if err := os.Rename(src, dest); err != nil {
+		if old != "" {
+			os.Rename(old, dest)
+		}
+		return fmt.Errorf("failed to move SDK into %s: %w", dest, err)
+	}
+
+	if old != "" {
+		os.RemoveAll(old)
+	}
+	return nil
+}
+
+// isEmptyDir reports whether path is missing or an empty directory
+func isEmptyDir(path string) bool {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return true
+	}
+	return err == nil && len(entries) == 0
+}
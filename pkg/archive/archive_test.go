@@ -0,0 +1,285 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+type entry struct {
+	name string
+	body string
+	mode os.FileMode
+	link string
+	// hardlink names the entry a tar hardlink points at
+	hardlink string
+}
+
+var sdkEntries = []entry{
+	{name: "flutter/", mode: os.ModeDir | 0755},
+	{name: "flutter/bin/flutter", body: "#!/bin/sh\n", mode: 0755},
+	{name: "flutter/README.md", body: "Flutter\n", mode: 0644},
+	{name: "flutter/bin/cache/dart", link: "../flutter", mode: os.ModeSymlink | 0777},
+}
+
+func writeZip(t *testing.T, path string, entries []entry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		hdr.SetMode(e.mode)
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := e.body
+		if e.link != "" {
+			body = e.link
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarXz(t *testing.T, path string, entries []entry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	xw, err := xz.NewWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(xw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: int64(e.mode.Perm()), Size: int64(len(e.body))}
+		switch {
+		case e.mode.IsDir():
+			hdr.Typeflag = tar.TypeDir
+		case e.link != "":
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = e.link
+		case e.hardlink != "":
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = e.hardlink
+		default:
+			hdr.Typeflag = tar.TypeReg
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e.body))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	for _, format := range []string{"zip", "tar.xz"} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "flutter_sdk."+format)
+			if format == "zip" {
+				writeZip(t, src, sdkEntries)
+			} else {
+				writeTarXz(t, src, sdkEntries)
+			}
+
+			dest := filepath.Join(dir, "sdk", "flutter")
+			var percents []int
+			var statuses []string
+			err := New().Extract(context.Background(), src, dest, func(percent int, status string) {
+				percents = append(percents, percent)
+				statuses = append(statuses, status)
+			})
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+
+			// The top-level flutter/ folder becomes dest itself
+			body, err := os.ReadFile(filepath.Join(dest, "bin", "flutter"))
+			if err != nil || string(body) != "#!/bin/sh\n" {
+				t.Fatalf("bin/flutter = %q, %v", body, err)
+			}
+
+			if runtime.GOOS != "windows" {
+				info, _ := os.Stat(filepath.Join(dest, "bin", "flutter"))
+				if info.Mode().Perm()&0100 == 0 {
+					t.Errorf("bin/flutter mode = %v, want executable", info.Mode())
+				}
+				link, err := os.Readlink(filepath.Join(dest, "bin", "cache", "dart"))
+				if err != nil || link != "../flutter" {
+					t.Errorf("symlink = %q, %v, want ../flutter", link, err)
+				}
+			}
+
+			if !strings.Contains(strings.Join(statuses, "\n"), "Extracting flutter/README.md") {
+				t.Errorf("no per-file progress in %q", statuses)
+			}
+			if percents[len(percents)-1] != 100 {
+				t.Errorf("last progress = %d, want 100", percents[len(percents)-1])
+			}
+
+			// Nothing but the SDK is left next to dest
+			if entries, _ := os.ReadDir(filepath.Dir(dest)); len(entries) != 1 {
+				t.Errorf("temporary files left behind: %v", entries)
+			}
+		})
+	}
+}
+
+func TestExtractRejectsPathTraversal(t *testing.T) {
+	tests := map[string][]entry{
+		"parent":   {{name: "flutter/ok", body: "ok", mode: 0644}, {name: "../evil", body: "x", mode: 0644}},
+		"nested":   {{name: "flutter/../../evil", body: "x", mode: 0644}},
+		"absolute": {{name: "/tmp/evil", body: "x", mode: 0644}},
+		"symlink":  {{name: "flutter/link", link: "../../..", mode: os.ModeSymlink | 0777}},
+		// Each link is inside the archive on its own, but l2 is created
+		// through l1 and so really points two levels above flutter/
+		"symlink chain": {
+			{name: "flutter/l1", link: ".", mode: os.ModeSymlink | 0777},
+			{name: "flutter/l1/l2", link: "../..", mode: os.ModeSymlink | 0777},
+			{name: "flutter/l2/evil", body: "x", mode: 0644},
+		},
+		"symlink climb": {
+			{name: "flutter/up", link: "..", mode: os.ModeSymlink | 0777},
+			{name: "flutter/link", link: "up/../..", mode: os.ModeSymlink | 0777},
+		},
+	}
+
+	for name, entries := range tests {
+		for _, format := range []string{"zip", "tar.xz"} {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				dir := t.TempDir()
+				src := filepath.Join(dir, "evil."+format)
+				if format == "zip" {
+					writeZip(t, src, entries)
+				} else {
+					writeTarXz(t, src, entries)
+				}
+
+				dest := filepath.Join(dir, "out", "flutter")
+				err := New().Extract(context.Background(), src, dest, nil)
+				if err == nil {
+					t.Fatal("Extract() should reject entries outside the destination")
+				}
+				for _, evil := range []string{filepath.Join(dir, "evil"), filepath.Join(dir, "out", "evil")} {
+					if _, err := os.Stat(evil); !os.IsNotExist(err) {
+						t.Error("file written outside the destination")
+					}
+				}
+				if _, err := os.Stat(dest); !os.IsNotExist(err) {
+					t.Error("dest should not exist after a failed extraction")
+				}
+				if entries, _ := os.ReadDir(filepath.Dir(dest)); len(entries) != 0 {
+					t.Errorf("temporary files left behind: %v", entries)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractRejectsHardlinkThroughSymlink(t *testing.T) {
+	tests := map[string]string{
+		// A hardlink to a symlink would move its relative target
+		"to symlink":      "flutter/bin/cache",
+		"through symlink": "flutter/bin/cache/flutter",
+	}
+	for name, source := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "evil.tar.xz")
+			writeTarXz(t, src, []entry{
+				{name: "flutter/bin/flutter", body: "#!/bin/sh\n", mode: 0755},
+				{name: "flutter/bin/cache", link: ".", mode: os.ModeSymlink | 0777},
+				{name: "flutter/linked", hardlink: source, mode: 0644},
+			})
+
+			err := New().Extract(context.Background(), src, filepath.Join(dir, "out", "flutter"), nil)
+			if err == nil || !strings.Contains(err.Error(), "symlink") {
+				t.Errorf("Extract() error = %v, want the hardlink through the symlink rejected", err)
+			}
+		})
+	}
+}
+
+func TestExtractCancelled(t *testing.T) {
+	for _, format := range []string{"zip", "tar.xz"} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "flutter_sdk."+format)
+			if format == "zip" {
+				writeZip(t, src, sdkEntries)
+			} else {
+				writeTarXz(t, src, sdkEntries)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			dest := filepath.Join(dir, "sdk", "flutter")
+			err := New().Extract(ctx, src, dest, func(percent int, status string) {
+				// Ctrl+C while the first entry is written
+				cancel()
+			})
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Extract() error = %v, want context.Canceled", err)
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(dest)); len(entries) != 0 {
+				t.Errorf("files left behind after cancelling: %v", entries)
+			}
+		})
+	}
+}
+
+func TestExtractOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "flutter.zip")
+	writeZip(t, src, sdkEntries)
+
+	dest := filepath.Join(dir, "flutter")
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dest, "stale"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := New().Extract(context.Background(), src, dest, nil); err == nil {
+		t.Fatal("Extract() should refuse a non-empty destination")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "stale")); err != nil {
+		t.Fatal("refused extraction must leave dest untouched")
+	}
+
+	if err := (&Extractor{Overwrite: true}).Extract(context.Background(), src, dest, nil); err != nil {
+		t.Fatalf("Extract() with Overwrite error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "stale")); !os.IsNotExist(err) {
+		t.Error("old files should be replaced")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "README.md")); err != nil {
+		t.Error("new SDK not in place")
+	}
+}
@@ -0,0 +1,95 @@
+package doctor
+
+// ChangeKind describes how a category differs between two runs
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"   // only in the newer run
+	ChangeRemoved ChangeKind = "removed" // only in the older run
+	ChangeStatus  ChangeKind = "status"  // the status changed
+	ChangeDetails ChangeKind = "details" // same status, different summary or hints
+)
+
+// Change is one category that differs between two runs
+type Change struct {
+	Kind   ChangeKind `json:"kind"`
+	Name   string     `json:"name"`
+	Before *Category  `json:"before,omitempty"`
+	After  *Category  `json:"after,omitempty"`
+	// NewHints are hints and errors of After that Before did not have
+	NewHints []Message `json:"new_hints"`
+	// ResolvedHints are hints and errors of Before that After no longer has
+	ResolvedHints []Message `json:"resolved_hints"`
+}
+
+// Diff compares two reports category by category and returns the
+// categories that changed, in the order of after followed by the ones
+// that were removed. Categories are matched by name; when a name repeats,
+// such as two Android Studio installs, the nth occurrences are matched
+func Diff(before, after *Report) []Change {
+	remaining := make(map[string][]int)
+	for i, c := range before.Categories {
+		remaining[c.Name] = append(remaining[c.Name], i)
+	}
+
+	changes := []Change{}
+	matched := make(map[int]bool)
+	for i := range after.Categories {
+		a := &after.Categories[i]
+		queue := remaining[a.Name]
+		if len(queue) == 0 {
+			changes = append(changes, Change{Kind: ChangeAdded, Name: a.Name, After: a, NewHints: missingMessages(a.Hints(), nil)})
+			continue
+		}
+		remaining[a.Name] = queue[1:]
+		matched[queue[0]] = true
+
+		if change, ok := diffCategory(&before.Categories[queue[0]], a); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	for i := range before.Categories {
+		if !matched[i] {
+			b := &before.Categories[i]
+			changes = append(changes, Change{Kind: ChangeRemoved, Name: b.Name, Before: b, ResolvedHints: missingMessages(b.Hints(), nil)})
+		}
+	}
+	return changes
+}
+
+func diffCategory(before, after *Category) (Change, bool) {
+	change := Change{
+		Name:          after.Name,
+		Before:        before,
+		After:         after,
+		NewHints:      missingMessages(after.Hints(), before.Hints()),
+		ResolvedHints: missingMessages(before.Hints(), after.Hints()),
+	}
+
+	switch {
+	case before.Status != after.Status:
+		change.Kind = ChangeStatus
+	case before.Summary != after.Summary || len(change.NewHints) > 0 || len(change.ResolvedHints) > 0:
+		change.Kind = ChangeDetails
+	default:
+		return Change{}, false
+	}
+	return change, true
+}
+
+// missingMessages returns the messages of a whose text is not in b
+func missingMessages(a, b []Message) []Message {
+	seen := make(map[string]bool, len(b))
+	for _, m := range b {
+		seen[m.Text] = true
+	}
+
+	out := []Message{}
+	for _, m := range a {
+		if !seen[m.Text] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package doctor
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := Parse(`[✓] Flutter (Channel stable, 3.24.5, on macOS 14.6.1 23G93 darwin-arm64, locale en-GB)
+[✓] Android toolchain - develop for Android devices (Android SDK version 34.0.0)
+[!] Xcode - develop for iOS and macOS (Xcode 15.4)
+    ✗ CocoaPods not installed.
+[✓] Android Studio (version 2024.1)
+[✓] Android Studio (version 2023.3)
+[✓] Chrome - develop for the web
+
+! Doctor found issues in 1 category.
+`)
+	after := Parse(`[✓] Flutter (Channel stable, 3.24.5, on macOS 14.6.1 23G93 darwin-arm64, locale en-GB)
+[✗] Android toolchain - develop for Android devices
+    ✗ Unable to locate Android SDK.
+[✓] Xcode - develop for iOS and macOS (Xcode 15.4)
+[✓] Android Studio (version 2024.1)
+[✓] Android Studio (version 2024.2)
+[✓] Connected device (2 available)
+
+! Doctor found issues in 1 category.
+`)
+
+	changes := Diff(before, after)
+
+	want := []struct {
+		kind     ChangeKind
+		name     string
+		newHints int
+		resolved int
+	}{
+		{ChangeStatus, "Android toolchain - develop for Android devices", 1, 0},
+		{ChangeStatus, "Xcode - develop for iOS and macOS", 0, 1},
+		{ChangeDetails, "Android Studio", 0, 0},
+		{ChangeAdded, "Connected device", 0, 0},
+		{ChangeRemoved, "Chrome - develop for the web", 0, 0},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Diff returned %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Kind != w.kind || c.Name != w.name || len(c.NewHints) != w.newHints || len(c.ResolvedHints) != w.resolved {
+			t.Errorf("change %d = %s %q (+%d -%d), want %s %q (+%d -%d)",
+				i, c.Kind, c.Name, len(c.NewHints), len(c.ResolvedHints), w.kind, w.name, w.newHints, w.resolved)
+		}
+	}
+
+	if got := changes[0]; got.Before.Status != StatusOK || got.After.Status != StatusMissing {
+		t.Errorf("Android toolchain went %s → %s, want ok → missing", got.Before.Status, got.After.Status)
+	}
+	if got := changes[2]; got.Before.Summary != "version 2023.3" || got.After.Summary != "version 2024.2" {
+		t.Errorf("second Android Studio matched %q → %q", got.Before.Summary, got.After.Summary)
+	}
+}
+
+func TestDiffIdentical(t *testing.T) {
+	report := &Report{Categories: []Category{
+		{Status: StatusPartial, Name: "Android toolchain", Messages: []Message{{Type: MessageHint, Text: "Some licenses not accepted"}}},
+	}}
+	if changes := Diff(report, report); len(changes) != 0 {
+		t.Errorf("Diff of identical reports = %+v, want none", changes)
+	}
+}
@@ -0,0 +1,141 @@
+package doctor
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Status is the outcome of one flutter doctor category
+type Status string
+
+const (
+	StatusOK      Status = "ok"      // [✓]
+	StatusPartial Status = "partial" // [!]
+	StatusMissing Status = "missing" // [✗]
+	StatusCrash   Status = "crash"   // [☠] the check itself crashed
+)
+
+// Symbol returns the glyph flutter doctor prints for the status
+func (s Status) Symbol() string {
+	switch s {
+	case StatusOK:
+		return "✓"
+	case StatusPartial:
+		return "!"
+	case StatusMissing:
+		return "✗"
+	case StatusCrash:
+		return "☠"
+	default:
+		return "?"
+	}
+}
+
+// MessageType tells details apart from the hints that need action
+type MessageType string
+
+const (
+	MessageInfo  MessageType = "info"  // •
+	MessageHint  MessageType = "hint"  // !
+	MessageError MessageType = "error" // ✗
+)
+
+// Message is one bullet under a category. Indented lines that follow a
+// bullet, such as the command to run, are part of its Text
+type Message struct {
+	Type MessageType `json:"type"`
+	Text string      `json:"text"`
+}
+
+// Category is one validator in the doctor output, e.g. "Android toolchain"
+type Category struct {
+	Status Status `json:"status"`
+	// Name is the header without the trailing parenthesis, e.g.
+	// "Android toolchain - develop for Android devices"
+	Name string `json:"name"`
+	// Summary is the text in the trailing parenthesis of the header, e.g.
+	// "Android SDK version 34.0.0"
+	Summary  string    `json:"summary"`
+	Messages []Message `json:"messages"`
+}
+
+// Failed reports whether the category is missing or crashed
+func (c Category) Failed() bool {
+	return c.Status == StatusMissing || c.Status == StatusCrash
+}
+
+// Details returns the informational bullets
+func (c Category) Details() []Message {
+	return c.filter(MessageInfo)
+}
+
+// Hints returns the bullets that ask the user to fix something
+func (c Category) Hints() []Message {
+	return append(c.filter(MessageError), c.filter(MessageHint)...)
+}
+
+func (c Category) filter(t MessageType) []Message {
+	var out []Message
+	for _, m := range c.Messages {
+		if m.Type == t {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Report is the parsed output of flutter doctor
+type Report struct {
+	Categories []Category `json:"categories"`
+	// Summary is the closing line, e.g. "No issues found!" or
+	// "Doctor found issues in 2 categories."
+	Summary string `json:"summary"`
+}
+
+// Find returns the first category whose name starts with prefix,
+// ignoring case, or nil
+func (r *Report) Find(prefix string) *Category {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	for i, c := range r.Categories {
+		if strings.HasPrefix(strings.ToLower(c.Name), prefix) {
+			return &r.Categories[i]
+		}
+	}
+	return nil
+}
+
+// AndroidLicenseProblem returns the Android toolchain message saying the
+// SDK licences are not accepted or their status is unknown, or "" when
+// there is none. It reports false when the report has no Android
+// toolchain category to tell
+func (r *Report) AndroidLicenseProblem() (string, bool) {
+	android := r.Find("Android toolchain")
+	if android == nil {
+		return "", false
+	}
+	for _, m := range android.Hints() {
+		if strings.Contains(strings.ToLower(m.Text), "license") {
+			return m.Text, true
+		}
+	}
+	return "", true
+}
+
+// Issues returns the number of categories that are not OK
+func (r *Report) Issues() int {
+	n := 0
+	for _, c := range r.Categories {
+		if c.Status != StatusOK {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
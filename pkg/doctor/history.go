@@ -0,0 +1,134 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"flutter_takeoff/pkg/appdirs"
+)
+
+// DefaultHistoryLimit is how many runs a History keeps before the oldest
+// are deleted
+const DefaultHistoryLimit = 50
+
+// Run is one saved flutter doctor result
+type Run struct {
+	Time   time.Time `json:"time"`
+	Report *Report   `json:"report"`
+}
+
+// History stores doctor runs as one JSON file per run in Dir
+type History struct {
+	Dir string
+	// Limit is the number of runs kept; zero or less keeps everything
+	Limit int
+}
+
+// NewHistory returns the history kept in the tool's data directory
+func NewHistory() (*History, error) {
+	dir, err := appdirs.DataDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate data directory: %w", err)
+	}
+	return &History{Dir: filepath.Join(dir, "doctor-history"), Limit: DefaultHistoryLimit}, nil
+}
+
+// Save stores report with the time t and prunes the oldest runs above
+// the limit
+func (h *History) Save(t time.Time, report *Report) error {
+	if err := os.MkdirAll(h.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(Run{Time: t, Report: report}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a half-written run
+	path := filepath.Join(h.Dir, runFileName(t))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to save doctor run: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save doctor run: %w", err)
+	}
+
+	return h.prune()
+}
+
+// Runs returns the saved runs, oldest first. Files that cannot be read
+// are skipped
+func (h *History) Runs() ([]Run, error) {
+	names, err := h.files()
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []Run
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(h.Dir, name))
+		if err != nil {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(data, &run); err != nil || run.Report == nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Time.Before(runs[j].Time)
+	})
+	return runs, nil
+}
+
+// files returns the names of the run files, which sort by time
+func (h *History) files() ([]string, error) {
+	entries, err := os.ReadDir(h.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (h *History) prune() error {
+	if h.Limit <= 0 {
+		return nil
+	}
+	names, err := h.files()
+	if err != nil {
+		return err
+	}
+	for len(names) > h.Limit {
+		if err := os.Remove(filepath.Join(h.Dir, names[0])); err != nil {
+			return fmt.Errorf("failed to prune history: %w", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// runFileName names a run after its UTC time so that names sort by time
+func runFileName(t time.Time) string {
+	return t.UTC().Format("20060102T150405.000000000Z") + ".json"
+}
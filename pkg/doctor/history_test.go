@@ -0,0 +1,63 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistorySaveAndRuns(t *testing.T) {
+	h := &History{Dir: filepath.Join(t.TempDir(), "history"), Limit: 3}
+
+	runs, err := h.Runs()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("Runs of a missing directory = %v, %v; want none", runs, err)
+	}
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		report := &Report{
+			Categories: []Category{{Status: StatusOK, Name: "Flutter"}},
+			Summary:    "No issues found!",
+		}
+		if i == 4 {
+			report.Categories[0].Status = StatusMissing
+		}
+		if err := h.Save(start.Add(time.Duration(i)*time.Hour), report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err = h.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("kept %d runs, want 3", len(runs))
+	}
+	if !runs[0].Time.Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("oldest kept run is from %v, want %v", runs[0].Time, start.Add(2*time.Hour))
+	}
+	if got := runs[2].Report.Categories[0].Status; got != StatusMissing {
+		t.Errorf("latest run status = %s, want %s", got, StatusMissing)
+	}
+}
+
+func TestHistorySkipsUnreadableRuns(t *testing.T) {
+	h := &History{Dir: t.TempDir()}
+	if err := h.Save(time.Now(), &Report{Categories: []Category{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(h.Dir, "broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := h.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("got %d runs, want the 1 readable run", len(runs))
+	}
+}
@@ -0,0 +1,128 @@
+package doctor
+
+import (
+	"bufio"
+	"strings"
+)
+
+// Glyphs flutter doctor uses for category statuses. Consoles without
+// Unicode support get √ and X instead of ✓ and ✗
+var statusGlyphs = map[string]Status{
+	"✓": StatusOK,
+	"√": StatusOK,
+	"!": StatusPartial,
+	"✗": StatusMissing,
+	"X": StatusMissing,
+	"☠": StatusCrash,
+}
+
+// Glyphs that start a bullet inside a category
+var messageGlyphs = map[string]MessageType{
+	"•": MessageInfo,
+	"!": MessageHint,
+	"✗": MessageError,
+	"X": MessageError,
+}
+
+// Parse turns flutter doctor output, verbose or not, into a Report.
+// Lines it does not recognise, such as download progress printed before
+// the checks, are skipped
+func Parse(output string) *Report {
+	report := &Report{Categories: []Category{}}
+	var current *Category
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		text := strings.TrimSpace(line)
+
+		if indent == 0 {
+			if category, ok := parseHeader(text); ok {
+				report.Categories = append(report.Categories, category)
+				current = &report.Categories[len(report.Categories)-1]
+				continue
+			}
+			if summary, ok := parseSummary(text); ok {
+				report.Summary = summary
+			}
+			// Anything else at the top level ends the current category
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		glyph, rest, _ := strings.Cut(text, " ")
+		if t, ok := messageGlyphs[glyph]; ok {
+			current.Messages = append(current.Messages, Message{Type: t, Text: strings.TrimSpace(rest)})
+			continue
+		}
+
+		// A continuation of the previous bullet, e.g. the command to run
+		if n := len(current.Messages); n > 0 {
+			current.Messages[n-1].Text += "\n" + text
+		}
+	}
+
+	return report
+}
+
+// parseHeader parses a "[✓] Name (summary)" category line
+func parseHeader(line string) (Category, bool) {
+	if len(line) < 4 || line[0] != '[' {
+		return Category{}, false
+	}
+	glyph, rest, ok := strings.Cut(line[1:], "] ")
+	if !ok {
+		return Category{}, false
+	}
+	status, ok := statusGlyphs[glyph]
+	if !ok {
+		return Category{}, false
+	}
+
+	name, summary := splitSummary(strings.TrimSpace(rest))
+	return Category{Status: status, Name: name, Summary: summary, Messages: []Message{}}, true
+}
+
+// splitSummary separates a trailing, balanced parenthesis from a header
+func splitSummary(header string) (string, string) {
+	if !strings.HasSuffix(header, ")") {
+		return header, ""
+	}
+
+	depth := 0
+	for i := len(header) - 1; i >= 0; i-- {
+		switch header[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(header[:i]), header[i+1 : len(header)-1]
+			}
+		}
+	}
+	return header, ""
+}
+
+// parseSummary recognises the closing "• No issues found!" or
+// "! Doctor found issues in N categories." line
+func parseSummary(line string) (string, bool) {
+	glyph, rest, ok := strings.Cut(line, " ")
+	if !ok || (glyph != "•" && glyph != "!") {
+		return "", false
+	}
+	if strings.HasPrefix(rest, "No issues found") || strings.HasPrefix(rest, "Doctor found issues") {
+		return rest, true
+	}
+	return "", false
+}
@@ -0,0 +1,175 @@
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestParseGolden parses doctor output captured from several Flutter
+// versions and compares the result with testdata/*.golden.json
+func TestParseGolden(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no captured doctor output in testdata")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".txt")
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.MarshalIndent(Parse(string(raw)), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(input, ".txt") + ".golden.json"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Parse(%s) differs from %s:\n%s", input, golden, got)
+			}
+		})
+	}
+}
+
+func readReport(t *testing.T, name string) *Report {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Parse(string(raw))
+}
+
+func TestParseVerbose(t *testing.T) {
+	report := readReport(t, "flutter_3.24.5_macos_verbose.txt")
+
+	if len(report.Categories) != 8 {
+		t.Fatalf("got %d categories, want 8", len(report.Categories))
+	}
+	if report.Issues() != 2 || report.Summary != "Doctor found issues in 2 categories." {
+		t.Errorf("Issues() = %d, Summary = %q", report.Issues(), report.Summary)
+	}
+
+	android := report.Find("android toolchain")
+	if android == nil {
+		t.Fatal("Android toolchain not found")
+	}
+	if android.Status != StatusPartial || android.Name != "Android toolchain - develop for Android devices" ||
+		android.Summary != "Android SDK version 34.0.0" {
+		t.Errorf("unexpected header %+v", android)
+	}
+	if len(android.Details()) != 1 || len(android.Hints()) != 2 {
+		t.Fatalf("details %v, hints %v", android.Details(), android.Hints())
+	}
+	licence := android.Hints()[1].Text
+	if !strings.HasPrefix(licence, "Android license status unknown.\nRun `flutter doctor --android-licenses`") {
+		t.Errorf("continuation lines not attached to the hint: %q", licence)
+	}
+
+	flutter := report.Find("Flutter")
+	if flutter.Summary != "Channel stable, 3.24.5, on macOS 14.5 23F79 darwin-arm64, locale en-GB" {
+		t.Errorf("Flutter summary = %q", flutter.Summary)
+	}
+}
+
+func TestParseStatuses(t *testing.T) {
+	tests := []struct {
+		file     string
+		category string
+		want     Status
+		failed   bool
+	}{
+		{"flutter_3.16.9_linux_verbose.txt", "Network resources", StatusCrash, true},
+		{"flutter_3.16.9_linux_verbose.txt", "Chrome", StatusMissing, true},
+		{"flutter_3.16.9_linux_verbose.txt", "Linux toolchain", StatusPartial, false},
+		{"flutter_2.10.5_windows_ascii.txt", "Flutter", StatusOK, false},
+		{"flutter_2.10.5_windows_ascii.txt", "Visual Studio", StatusMissing, true},
+		{"flutter_3.22.2_windows_summary.txt", "Android toolchain", StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		c := readReport(t, tt.file).Find(tt.category)
+		if c == nil {
+			t.Errorf("%s: %s not found", tt.file, tt.category)
+			continue
+		}
+		if c.Status != tt.want || c.Failed() != tt.failed {
+			t.Errorf("%s: %s status = %s (failed %v), want %s (failed %v)",
+				tt.file, tt.category, c.Status, c.Failed(), tt.want, tt.failed)
+		}
+	}
+}
+
+func TestAndroidLicenseProblem(t *testing.T) {
+	tests := []struct {
+		file  string
+		want  string
+		found bool
+	}{
+		{"flutter_3.24.5_macos_verbose.txt", "Android license status unknown.", true},
+		{"flutter_3.22.2_windows_summary.txt", "", true},
+		{"flutter_3.16.9_linux_verbose.txt", "", true},
+	}
+
+	for _, tt := range tests {
+		got, found := readReport(t, tt.file).AndroidLicenseProblem()
+		if !strings.HasPrefix(got, tt.want) || (tt.want == "") != (got == "") || found != tt.found {
+			t.Errorf("%s: AndroidLicenseProblem() = %q, %v; want %q, %v", tt.file, got, found, tt.want, tt.found)
+		}
+	}
+
+	if _, found := Parse("").AndroidLicenseProblem(); found {
+		t.Error("AndroidLicenseProblem() found an Android toolchain in empty output")
+	}
+}
+
+func TestParseSkipsNoise(t *testing.T) {
+	report := readReport(t, "flutter_3.22.2_windows_summary.txt")
+	if len(report.Categories) != 9 || report.Issues() != 0 || report.Summary != "No issues found!" {
+		t.Errorf("got %d categories, %d issues, summary %q", len(report.Categories), report.Issues(), report.Summary)
+	}
+
+	if got := Parse(""); len(got.Categories) != 0 {
+		t.Errorf("Parse(\"\") = %+v", got)
+	}
+}
+
+func TestSplitSummary(t *testing.T) {
+	tests := []struct{ header, name, summary string }{
+		{"Chrome - develop for the web", "Chrome - develop for the web", ""},
+		{"Android Studio (version 2024.1)", "Android Studio", "version 2024.1"},
+		{"Flutter (Channel stable, 2.10.5, on Microsoft Windows [Version 10.0.19044.1706], locale en-US)",
+			"Flutter", "Channel stable, 2.10.5, on Microsoft Windows [Version 10.0.19044.1706], locale en-US"},
+		{"Visual Studio (Visual Studio Build Tools 2019 (16.11.3))", "Visual Studio", "Visual Studio Build Tools 2019 (16.11.3)"},
+	}
+	for _, tt := range tests {
+		name, summary := splitSummary(tt.header)
+		if name != tt.name || summary != tt.summary {
+			t.Errorf("splitSummary(%q) = %q, %q", tt.header, name, summary)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressFunc receives the download percentage and a status line
+type ProgressFunc func(percent int, status string)
+
+// Downloader fetches files over HTTP and reports byte-based progress.
+// Interrupted downloads are kept as <dest>.part and resumed with HTTP
+// Range requests, both between retries and between runs
+type Downloader struct {
+	Client *http.Client
+	// StallTimeout ends an attempt when no bytes arrive for this long,
+	// so a stalled connection is retried like a dropped one. Zero waits
+	// forever
+	StallTimeout time.Duration
+	// Retries is how many times a transient failure is retried
+	Retries int
+	// Backoff is the delay before the first retry; it doubles on each
+	// further retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// New creates a Downloader whose client gives up on a server that stops
+// answering, before the headers or in the middle of the body
+func New() *Downloader {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &Downloader{
+		Client:       &http.Client{Transport: transport},
+		StallTimeout: time.Minute,
+		Retries:      5,
+		Backoff:      time.Second,
+		MaxBackoff:   30 * time.Second,
+	}
+}
+
+// transientError marks failures worth retrying
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// PartialPath returns where an unfinished download of dest is kept
+func PartialPath(dest string) string {
+	return dest + ".part"
+}
+
+// Download fetches url into dest. The file is written to PartialPath(dest)
+// and only renamed into place once the body has been read completely.
+// Cancelling ctx stops the download and any wait between retries
+func (d *Downloader) Download(ctx context.Context, url, dest string, progress ProgressFunc) error {
+	if progress == nil {
+		progress = func(int, string) {}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	name := filepath.Base(dest)
+	part := PartialPath(dest)
+	backoff := d.Backoff
+
+	// Retries keep the bar where it was, since the bytes saved so far
+	// are not downloaded again
+	last := 0
+	report := func(percent int, status string) {
+		last = percent
+		progress(percent, status)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = d.fetch(ctx, url, part, name, report)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to download %s: %w", name, ctx.Err())
+		}
+
+		var transient *transientError
+		if !errors.As(err, &transient) || attempt >= d.Retries {
+			// The partial file stays so the next run can resume it
+			return fmt.Errorf("failed to download %s: %w", name, err)
+		}
+
+		var saved int64
+		if info, err := os.Stat(part); err == nil {
+			saved = info.Size()
+		}
+		if saved == 0 {
+			last = 0
+		}
+		progress(last, fmt.Sprintf("Download interrupted (%v), retrying in %s with %s saved...", err, backoff, FormatBytes(saved)))
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("failed to download %s: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+		if d.MaxBackoff > 0 && backoff > d.MaxBackoff {
+			backoff = d.MaxBackoff
+		}
+	}
+
+	if err := os.Rename(part, dest); err != nil {
+		return fmt.Errorf("failed to save %s: %w", name, err)
+	}
+
+	var size int64
+	if info, err := os.Stat(dest); err == nil {
+		size = info.Size()
+	}
+	progress(100, fmt.Sprintf("Downloaded %s (%s)", name, FormatBytes(size)))
+	return nil
+}
+
+// fetch makes one request, resuming from the size of the partial file
+func (d *Downloader) fetch(ctx context.Context, url, part, name string, progress ProgressFunc) error {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return &transientError{err}
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && rangeStart(resp) == offset:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		// The range does not start where the partial file ends, so the
+		// bytes cannot be appended. Start over with the whole file
+		resp.Body.Close()
+		if err := os.Truncate(part, 0); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", part, err)
+		}
+		return d.fetch(ctx, url, part, name, progress)
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the Range header, start over
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is unusable, e.g. the archive changed upstream
+		os.Remove(part)
+		return &transientError{fmt.Errorf("server rejected resume from byte %d", offset)}
+	case resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode >= 500:
+		return &transientError{fmt.Errorf("server returned %s", resp.Status)}
+	default:
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	file, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", part, err)
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	counter := &progressWriter{
+		total:    total,
+		written:  offset,
+		progress: progress,
+		name:     name,
+		last:     -1,
+	}
+	counter.report()
+
+	// A connection can stay open without sending anything, which no
+	// client timeout notices once the body has started
+	body := io.Reader(resp.Body)
+	var stalled atomic.Bool
+	if d.StallTimeout > 0 {
+		timer := time.AfterFunc(d.StallTimeout, func() {
+			stalled.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+		body = &stallReader{r: resp.Body, timer: timer, timeout: d.StallTimeout}
+	}
+
+	_, err = io.Copy(io.MultiWriter(file, counter), body)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		return fmt.Errorf("failed to write %s: %w", part, closeErr)
+	}
+	if err != nil {
+		if stalled.Load() {
+			return &transientError{fmt.Errorf("no data received for %s", d.StallTimeout)}
+		}
+		return &transientError{err}
+	}
+	return nil
+}
+
+// stallReader restarts the stall timer whenever bytes arrive
+type stallReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (s *stallReader) Read(b []byte) (int, error) {
+	n, err := s.r.Read(b)
+	if n > 0 {
+		s.timer.Reset(s.timeout)
+	}
+	return n, err
+}
+
+// rangeStart returns the first byte position of a 206 response
+func rangeStart(resp *http.Response) int64 {
+	// Content-Range: bytes 100-199/200
+	spec, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	if !ok {
+		return -1
+	}
+	start, _, _ := strings.Cut(spec, "-")
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// progressWriter counts bytes and reports whenever the percentage changes
+type progressWriter struct {
+	total    int64
+	written  int64
+	progress ProgressFunc
+	name     string
+	last     int
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	p.report()
+	return len(b), nil
+}
+
+func (p *progressWriter) report() {
+	// Without a Content-Length only the byte count can be shown, once per MiB
+	if p.total <= 0 {
+		mib := int(p.written >> 20)
+		if mib == p.last {
+			return
+		}
+		p.last = mib
+		p.progress(0, fmt.Sprintf("Downloading %s: %s", p.name, FormatBytes(p.written)))
+		return
+	}
+
+	percent := int(p.written * 100 / p.total)
+	if percent == p.last {
+		return
+	}
+	p.last = percent
+	p.progress(percent, fmt.Sprintf("Downloading %s: %s / %s",
+		p.name, FormatBytes(p.written), FormatBytes(p.total)))
+}
+
+// FormatBytes renders a byte count using binary units
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,79 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownload(t *testing.T) {
+	payload := bytes.Repeat([]byte("flutter"), 50_000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/releases/flutter.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "flutter.zip", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.zip")
+	var percents []int
+	err := New().Download(context.Background(), server.URL+"/releases/flutter.zip", dest, func(percent int, status string) {
+		percents = append(percents, percent)
+	})
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("downloaded %d bytes, want %d", len(got), len(payload))
+	}
+
+	if len(percents) < 3 || percents[0] != 0 || percents[len(percents)-1] != 100 {
+		t.Errorf("progress = %v, want 0 ... 100", percents)
+	}
+	for i := 1; i < len(percents); i++ {
+		if percents[i] < percents[i-1] {
+			t.Fatalf("progress went backwards: %v", percents)
+		}
+	}
+}
+
+func TestDownloadNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "missing.zip")
+	err := New().Download(context.Background(), server.URL+"/missing.zip", dest, nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Download() error = %v, want 404", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("%s should not exist after a failed download", dest)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:           "512 B",
+		1536:          "1.5 KiB",
+		1 << 30:       "1.0 GiB",
+		1_288_490_188: "1.2 GiB",
+	}
+	for n, want := range tests {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer serves payload with Range support, but the first drops
+// responses are cut off after cut bytes by closing the connection
+type flakyServer struct {
+	payload []byte
+	drops   int
+	cut     int
+	status  int // when set, returned instead of the payload for the first drops requests
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	attempt := len(s.ranges)
+	s.mu.Unlock()
+
+	if attempt > s.drops {
+		http.ServeContent(w, r, "flutter.tar.xz", time.Time{}, bytes.NewReader(s.payload))
+		return
+	}
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	http.ServeContent(&droppingWriter{ResponseWriter: w, remaining: s.cut}, r,
+		"flutter.tar.xz", time.Time{}, bytes.NewReader(s.payload))
+}
+
+func (s *flakyServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+// droppingWriter writes up to remaining bytes and then kills the connection
+type droppingWriter struct {
+	http.ResponseWriter
+	remaining int
+	dropped   bool
+}
+
+func (w *droppingWriter) Write(b []byte) (int, error) {
+	if w.dropped {
+		return 0, http.ErrAbortHandler
+	}
+	if len(b) <= w.remaining {
+		w.remaining -= len(b)
+		return w.ResponseWriter.Write(b)
+	}
+
+	n, _ := w.ResponseWriter.Write(b[:w.remaining])
+	w.dropped = true
+	w.ResponseWriter.(http.Flusher).Flush()
+	if conn, _, err := w.ResponseWriter.(http.Hijacker).Hijack(); err == nil {
+		conn.Close()
+	}
+	return n, http.ErrAbortHandler
+}
+
+func testDownloader() *Downloader {
+	d := New()
+	d.Retries = 3
+	d.Backoff = time.Millisecond
+	d.MaxBackoff = 5 * time.Millisecond
+	return d
+}
+
+func testPayload() []byte {
+	return bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+}
+
+func TestDownloadResumesAfterDroppedConnections(t *testing.T) {
+	payload := testPayload()
+	flaky := &flakyServer{payload: payload, drops: 2, cut: len(payload) / 3}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	var retries int
+	err := testDownloader().Download(context.Background(), server.URL, dest, func(percent int, status string) {
+		if strings.HasPrefix(status, "Download interrupted") {
+			retries++
+			// The bar stays at the bytes already saved
+			if percent == 0 || !strings.Contains(status, "KiB saved") {
+				t.Errorf("retry progress = %d%% %q, want the saved bytes", percent, status)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("downloaded %d bytes, want %d identical bytes", len(got), len(payload))
+	}
+	if _, err := os.Stat(PartialPath(dest)); !os.IsNotExist(err) {
+		t.Error("partial file should be renamed once complete")
+	}
+
+	ranges := flaky.requests()
+	if len(ranges) != 3 || retries != 2 {
+		t.Fatalf("got %d requests and %d retries, want 3 and 2", len(ranges), retries)
+	}
+	if ranges[0] != "" {
+		t.Errorf("first request sent Range %q", ranges[0])
+	}
+	if ranges[1] == "" || ranges[2] == "" || ranges[1] == ranges[2] {
+		t.Errorf("retries should resume from further along, got Range %q then %q", ranges[1], ranges[2])
+	}
+}
+
+func TestDownloadResumesPartialFileFromPreviousRun(t *testing.T) {
+	payload := testPayload()
+	flaky := &flakyServer{payload: payload}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	half := len(payload) / 2
+	if err := os.WriteFile(PartialPath(dest), payload[:half], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var first int = -1
+	err := testDownloader().Download(context.Background(), server.URL, dest, func(percent int, status string) {
+		if first < 0 {
+			first = percent
+		}
+	})
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, payload) {
+		t.Fatal("resumed download does not match the payload")
+	}
+	if ranges := flaky.requests(); len(ranges) != 1 || ranges[0] != "bytes=524288-" {
+		t.Errorf("requests = %q, want a single Range: bytes=524288-", ranges)
+	}
+	if first != 50 {
+		t.Errorf("first progress = %d%%, want resume at 50%%", first)
+	}
+}
+
+func TestDownloadRestartsWhenRangeIgnored(t *testing.T) {
+	payload := testPayload()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	if err := os.WriteFile(PartialPath(dest), []byte("stale bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testDownloader().Download(context.Background(), server.URL, dest, nil); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, payload) {
+		t.Fatal("download should start over when the server ignores Range")
+	}
+}
+
+func TestDownloadRestartsWhenRangeStartsElsewhere(t *testing.T) {
+	payload := testPayload()
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if r.Header.Get("Range") == "" {
+			w.Write(payload)
+			return
+		}
+		// A partial response that starts at byte 0 instead of the offset
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(payload)-1, len(payload)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	if err := os.WriteFile(PartialPath(dest), payload[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := testDownloader()
+	d.Retries = 0
+	if err := d.Download(context.Background(), server.URL, dest, nil); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, payload) {
+		t.Fatal("download should start over when the range starts elsewhere")
+	}
+	if len(ranges) != 2 || ranges[0] != "bytes=1000-" || ranges[1] != "" {
+		t.Errorf("requests = %q, want a resume and then a full download", ranges)
+	}
+}
+
+func TestDownloadRetriesServerErrors(t *testing.T) {
+	payload := testPayload()
+	flaky := &flakyServer{payload: payload, drops: 2, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	if err := testDownloader().Download(context.Background(), server.URL, dest, nil); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if n := len(flaky.requests()); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+}
+
+func TestDownloadGivesUpAndKeepsPartialFile(t *testing.T) {
+	payload := testPayload()
+	flaky := &flakyServer{payload: payload, drops: 10, cut: 1000}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	if err := testDownloader().Download(context.Background(), server.URL, dest, nil); err == nil {
+		t.Fatal("Download() should fail once retries are exhausted")
+	}
+	if n := len(flaky.requests()); n != 4 {
+		t.Errorf("got %d requests, want 1 + 3 retries", n)
+	}
+
+	info, err := os.Stat(PartialPath(dest))
+	if err != nil {
+		t.Fatalf("partial file should be kept for the next run: %v", err)
+	}
+	if info.Size() != 4000 {
+		t.Errorf("partial file has %d bytes, want 4000", info.Size())
+	}
+}
+
+func TestDownloadDoesNotRetryClientErrors(t *testing.T) {
+	flaky := &flakyServer{drops: 10, status: http.StatusForbidden}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	if err := testDownloader().Download(context.Background(), server.URL, dest, nil); err == nil {
+		t.Fatal("Download() should fail on 403")
+	}
+	if n := len(flaky.requests()); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
+
+func TestDownloadRetriesStalledConnections(t *testing.T) {
+	payload := testPayload()
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			http.ServeContent(w, r, "flutter.tar.xz", time.Time{}, bytes.NewReader(payload))
+			return
+		}
+		// Send part of the body, then keep the connection open silently
+		w.Header().Set("Content-Length", fmt.Sprint(len(payload)))
+		w.Write(payload[:1000])
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	d := testDownloader()
+	d.StallTimeout = 50 * time.Millisecond
+	dest := filepath.Join(t.TempDir(), "flutter.tar.xz")
+	var stall string
+	err := d.Download(context.Background(), server.URL, dest, func(percent int, status string) {
+		if strings.HasPrefix(status, "Download interrupted") {
+			stall = status
+		}
+	})
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, payload) {
+		t.Fatal("download after the stall does not match the payload")
+	}
+	if requests != 2 || !strings.Contains(stall, "no data received") {
+		t.Errorf("got %d requests and retry status %q, want the stall retried once", requests, stall)
+	}
+}
+
+func TestDownloadCancelledDuringBackoff(t *testing.T) {
+	flaky := &flakyServer{drops: 10, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	d := testDownloader()
+	d.Backoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Download(ctx, server.URL, filepath.Join(t.TempDir(), "flutter.tar.xz"), func(percent int, status string) {
+			if strings.HasPrefix(status, "Download interrupted") {
+				cancel()
+			}
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Download() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Download() kept waiting to retry after ctx was cancelled")
+	}
+	if n := len(flaky.requests()); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
@@ -0,0 +1,73 @@
+package download
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// ChecksumError reports a file whose contents do not match the expected hash
+type ChecksumError struct {
+	Path     string
+	Expected string
+	Actual   string
+	// Algorithm is "sha256" or "sha1"
+	Algorithm string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected %s %s, got %s", e.Path, e.Algorithm, e.Expected, e.Actual)
+}
+
+// FileSHA256 returns the hex-encoded SHA-256 digest of a file
+func FileSHA256(path string) (string, error) {
+	return fileDigest(path, sha256.New())
+}
+
+// FileSHA1 returns the hex-encoded SHA-1 digest of a file. Only use it
+// where the publisher offers nothing stronger, such as the Android SDK
+// repository
+func FileSHA1(path string) (string, error) {
+	return fileDigest(path, sha1.New())
+}
+
+func fileDigest(path string, h hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySHA256 checks path against the expected hex-encoded digest and
+// returns a *ChecksumError when they differ
+func VerifySHA256(path, expected string) error {
+	return verify(path, expected, "sha256", FileSHA256)
+}
+
+// VerifySHA1 checks path against the expected hex-encoded SHA-1 digest
+// and returns a *ChecksumError when they differ
+func VerifySHA1(path, expected string) error {
+	return verify(path, expected, "sha1", FileSHA1)
+}
+
+func verify(path, expected, algorithm string, digest func(string) (string, error)) error {
+	actual, err := digest(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return &ChecksumError{Path: path, Expected: expected, Actual: actual, Algorithm: algorithm}
+	}
+	return nil
+}
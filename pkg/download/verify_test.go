@@ -0,0 +1,49 @@
+package download
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifySHA256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.zip")
+	if err := os.WriteFile(path, []byte("flutter"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const sum = "be13961a0ab037c4fd82b1ecc42dcc7c2759f4c96143ff9cb5f8a36ac329745b"
+	if err := VerifySHA256(path, sum); err != nil {
+		t.Errorf("VerifySHA256() with the right digest = %v", err)
+	}
+	if err := VerifySHA256(path, "BE13961A0AB037C4FD82B1ECC42DCC7C2759F4C96143FF9CB5F8A36AC329745B"); err != nil {
+		t.Errorf("VerifySHA256() should ignore case, got %v", err)
+	}
+
+	const wrong = "0000000000000000000000000000000000000000000000000000000000000000"
+	err := VerifySHA256(path, wrong)
+	var checksumErr *ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Fatalf("VerifySHA256() with a wrong digest = %v, want *ChecksumError", err)
+	}
+	if checksumErr.Actual != sum || checksumErr.Expected != wrong {
+		t.Errorf("ChecksumError = %+v", checksumErr)
+	}
+}
+
+func TestVerifySHA1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commandlinetools.zip")
+	if err := os.WriteFile(path, []byte("flutter"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifySHA1(path, "ff25d17873bba4bc564d8c7217280aa254ed4541"); err != nil {
+		t.Errorf("VerifySHA1() with the right digest = %v", err)
+	}
+
+	var checksumErr *ChecksumError
+	if err := VerifySHA1(path, "0000000000000000000000000000000000000000"); !errors.As(err, &checksumErr) || checksumErr.Algorithm != "sha1" {
+		t.Errorf("VerifySHA1() with a wrong digest = %v, want a sha1 *ChecksumError", err)
+	}
+}
@@ -0,0 +1,176 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/archive"
+	"flutter_takeoff/pkg/download"
+	"flutter_takeoff/pkg/runner"
+)
+
+// DefaultAndroidPackages are the SDK packages installed when
+// InstallConfig.AndroidPackages is empty: what flutter doctor needs to
+// build an app for the current Android release
+var DefaultAndroidPackages = []string{"platform-tools", "platforms;android-34", "build-tools;34.0.0"}
+
+// sdkManagerTimeout bounds an sdkmanager run, which downloads every
+// package it installs
+const sdkManagerTimeout = 30 * time.Minute
+
+// ParseAndroidPackages splits a comma-separated package spec such as
+// "platform-tools, platforms;android-34" into sdkmanager package paths
+func ParseAndroidPackages(spec string) []string {
+	var packages []string
+	for _, p := range strings.Split(spec, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			packages = append(packages, p)
+		}
+	}
+	return packages
+}
+
+// sdkManagerPath returns where sdkmanager lives once the command-line
+// tools are installed into sdkRoot
+func sdkManagerPath(platform Platform, sdkRoot string) string {
+	name := "sdkmanager"
+	if platform == PlatformWindows {
+		name = "sdkmanager.bat"
+	}
+	return filepath.Join(sdkRoot, "cmdline-tools", "latest", "bin", name)
+}
+
+// androidRepositoryURL returns the configured repository, ending in "/"
+func androidRepositoryURL(config *InstallConfig) string {
+	base := config.AndroidRepositoryURL
+	if base == "" {
+		base = androidsdk.DefaultRepositoryURL
+	}
+	return strings.TrimRight(base, "/") + "/"
+}
+
+// installAndroidSDK installs the command-line tools into
+// config.AndroidSDKPath unless sdkmanager is already there, then runs
+// sdkmanager to install the configured packages. sdkmanager runs attached
+// to the terminal so the user can read and accept the SDK licences,
+// unless config.AcceptAndroidLicenses has accepted them already
+func installAndroidSDK(ctx context.Context, r runner.CommandRunner, config *InstallConfig, progressCallback func(percent int, status string)) error {
+	if config.AndroidSDKPath == "" {
+		return errors.New("no Android SDK path set")
+	}
+
+	sdkmanager := sdkManagerPath(config.Platform, config.AndroidSDKPath)
+	if _, err := os.Stat(sdkmanager); err != nil {
+		if err := downloadCmdlineTools(ctx, config, progressCallback); err != nil {
+			return err
+		}
+	} else {
+		progressCallback(100, "Using the command-line tools in "+filepath.Dir(filepath.Dir(sdkmanager)))
+	}
+
+	if config.AcceptAndroidLicenses {
+		progressCallback(100, "Accepting the Android SDK licences...")
+		licenses, err := androidLicenses(config)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the Android SDK licences: %w", err)
+		}
+		if err := writeAndroidLicenses(config, licenses); err != nil {
+			return err
+		}
+	}
+
+	packages := config.AndroidPackages
+	if len(packages) == 0 {
+		packages = DefaultAndroidPackages
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sdkManagerTimeout)
+	defer cancel()
+
+	args := append([]string{"--sdk_root=" + config.AndroidSDKPath, "--install"}, packages...)
+	if err := r.RunAttached(ctx, sdkmanager, args...); err != nil {
+		return fmt.Errorf("sdkmanager failed to install %s: %w", strings.Join(packages, ", "), err)
+	}
+	return nil
+}
+
+// androidLicenses fetches the texts of the licences writeAndroidLicenses
+// accepts, so the user can read them first
+func androidLicenses(config *InstallConfig) ([]androidsdk.License, error) {
+	repo, err := androidsdk.Fetch(http.DefaultClient, androidRepositoryURL(config)+androidsdk.RepositoryManifest)
+	if err != nil {
+		return nil, err
+	}
+	return repo.LicenseTexts(), nil
+}
+
+// writeAndroidLicenses accepts licenses without prompting, by writing
+// the hashes of their texts to the files sdkmanager --licenses leaves in
+// config.AndroidSDKPath/licenses once each licence is answered with y
+func writeAndroidLicenses(config *InstallConfig, licenses []androidsdk.License) error {
+	if config.AndroidSDKPath == "" {
+		return errors.New("no Android SDK path set")
+	}
+	return androidsdk.AcceptLicenses(config.AndroidSDKPath, licenses)
+}
+
+// downloadCmdlineTools downloads the latest command-line tools listed in
+// the Android repository and extracts them to cmdline-tools/latest, the
+// layout sdkmanager expects to find its SDK root from
+func downloadCmdlineTools(ctx context.Context, config *InstallConfig, progressCallback func(percent int, status string)) error {
+	progressCallback(0, "Looking up the Android command-line tools...")
+
+	base := androidRepositoryURL(config)
+	repo, err := androidsdk.Fetch(http.DefaultClient, base+androidsdk.RepositoryManifest)
+	if err != nil {
+		return err
+	}
+	archiveInfo, err := repo.Archive(androidsdk.CmdlineTools, androidsdk.HostOS(goos(config.Platform)))
+	if err != nil {
+		return err
+	}
+	if archiveInfo.SHA1() == "" {
+		return fmt.Errorf("Android repository has no sha1 for %s", archiveInfo.URL)
+	}
+
+	dir, err := downloadDir(config)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, path.Base(archiveInfo.URL))
+
+	if download.VerifySHA1(dest, archiveInfo.SHA1()) != nil {
+		if err := download.New().Download(ctx, base+archiveInfo.URL, dest, progressCallback); err != nil {
+			return fmt.Errorf("failed to download the Android command-line tools: %w", err)
+		}
+
+		progressCallback(100, "Verifying checksum...")
+		if err := download.VerifySHA1(dest, archiveInfo.SHA1()); err != nil {
+			os.Remove(dest)
+			var checksumErr *download.ChecksumError
+			if errors.As(err, &checksumErr) {
+				return fmt.Errorf("downloaded Android command-line tools are corrupt (sha1 %s, expected %s); the file was deleted, please retry",
+					checksumErr.Actual, checksumErr.Expected)
+			}
+			return fmt.Errorf("failed to verify the Android command-line tools: %w", err)
+		}
+	}
+
+	// The archive holds a single cmdline-tools folder, which becomes latest
+	latest := filepath.Join(config.AndroidSDKPath, "cmdline-tools", "latest")
+	progressCallback(0, "Extracting the Android command-line tools...")
+	if err := (&archive.Extractor{Overwrite: true}).Extract(ctx, dest, latest, progressCallback); err != nil {
+		return fmt.Errorf("failed to extract the Android command-line tools: %w", err)
+	}
+
+	progressCallback(100, "Android command-line tools installed to "+latest)
+	return nil
+}
@@ -0,0 +1,254 @@
+package installer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/runner"
+)
+
+const testRepository = `<?xml version="1.0" ?>
+<sdk:sdk-repository xmlns:sdk="http://schemas.android.com/sdk/android/repo/repository2/03">
+	<license id="android-sdk-license" type="text">Terms and Conditions</license>
+	<channel id="channel-0">stable</channel>
+	<remotePackage path="cmdline-tools;latest">
+		<revision><major>13</major><minor>0</minor></revision>
+		<channelRef ref="channel-0"/>
+		<archives>
+			<archive>
+				<complete>
+					<size>%d</size>
+					<checksum type="sha1">%s</checksum>
+					<url>commandlinetools-linux-11479570_latest.zip</url>
+				</complete>
+				<host-os>linux</host-os>
+			</archive>
+		</archives>
+	</remotePackage>
+</sdk:sdk-repository>`
+
+// testCmdlineToolsArchive builds a zip laid out like the real one, with
+// everything under a single cmdline-tools folder
+func testCmdlineToolsArchive(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"cmdline-tools/bin/sdkmanager", "cmdline-tools/lib/sdkmanager-classpath.jar"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("#!/bin/sh\n"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newAndroidRepositoryServer serves a repository manifest listing archive
+// and counts the archive downloads
+func newAndroidRepositoryServer(t *testing.T, archive []byte, checksum string) (*httptest.Server, *int) {
+	t.Helper()
+
+	downloads := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repository2-3.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, testRepository, len(archive), checksum)
+	})
+	mux.HandleFunc("/commandlinetools-linux-11479570_latest.zip", func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write(archive)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func sha1Hex(b []byte) string {
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestInstallAndroidSDK(t *testing.T) {
+	archive := testCmdlineToolsArchive(t)
+	server, downloads := newAndroidRepositoryServer(t, archive, sha1Hex(archive))
+
+	sdk := filepath.Join(t.TempDir(), "Android", "Sdk")
+	sdkmanager := filepath.Join(sdk, "cmdline-tools", "latest", "bin", "sdkmanager")
+	install := fmt.Sprintf("%s --sdk_root=%s --install platform-tools platforms;android-35", sdkmanager, sdk)
+	fake := runner.NewFake().On(install, runner.Result{})
+
+	config := &InstallConfig{
+		Platform:             PlatformLinux,
+		AndroidSDKPath:       sdk,
+		AndroidPackages:      ParseAndroidPackages("platform-tools, platforms;android-35,"),
+		AndroidRepositoryURL: server.URL,
+		DownloadDir:          t.TempDir(),
+	}
+	l := &LinuxInstaller{Config: config, Runner: fake}
+
+	if err := l.InstallAndroidSDK(context.Background(), func(int, string) {}); err != nil {
+		t.Fatalf("InstallAndroidSDK() error = %v", err)
+	}
+	if _, err := os.Stat(sdkmanager); err != nil {
+		t.Errorf("sdkmanager not extracted to cmdline-tools/latest: %v", err)
+	}
+	if got := fake.Calls(); !reflect.DeepEqual(got, []string{install}) {
+		t.Errorf("commands = %q, want %q", got, install)
+	}
+
+	// With the tools in place only sdkmanager runs again
+	if err := l.InstallAndroidSDK(context.Background(), func(int, string) {}); err != nil {
+		t.Fatalf("second InstallAndroidSDK() error = %v", err)
+	}
+	if *downloads != 1 {
+		t.Errorf("archive downloaded %d times, want 1", *downloads)
+	}
+}
+
+func TestInstallAndroidSDKChecksumMismatch(t *testing.T) {
+	archive := testCmdlineToolsArchive(t)
+	server, _ := newAndroidRepositoryServer(t, archive, strings.Repeat("0", 40))
+
+	sdk := filepath.Join(t.TempDir(), "Sdk")
+	config := &InstallConfig{
+		Platform:             PlatformLinux,
+		AndroidSDKPath:       sdk,
+		AndroidRepositoryURL: server.URL,
+		DownloadDir:          t.TempDir(),
+	}
+	fake := runner.NewFake()
+	err := installAndroidSDK(context.Background(), fake, config, func(int, string) {})
+	if err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("installAndroidSDK() error = %v, want a checksum error", err)
+	}
+	if _, err := os.Stat(filepath.Join(sdk, "cmdline-tools")); !os.IsNotExist(err) {
+		t.Error("a corrupt archive must not be extracted")
+	}
+	if len(fake.Calls()) != 0 {
+		t.Errorf("sdkmanager ran after a failed download: %q", fake.Calls())
+	}
+}
+
+func TestInstallAndroidSDKDefaultPackages(t *testing.T) {
+	sdk := t.TempDir()
+	sdkmanager := sdkManagerPath(PlatformLinux, sdk)
+	if err := os.MkdirAll(filepath.Dir(sdkmanager), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sdkmanager, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// sdkmanager exits 1 when a licence is declined
+	install := sdkmanager + " --sdk_root=" + sdk + " --install " + strings.Join(DefaultAndroidPackages, " ")
+	fake := runner.NewFake().On(install, runner.Result{ExitCode: 1})
+	config := &InstallConfig{Platform: PlatformLinux, AndroidSDKPath: sdk, AndroidRepositoryURL: "http://127.0.0.1:0"}
+
+	err := installAndroidSDK(context.Background(), fake, config, func(int, string) {})
+	if err == nil || !strings.Contains(err.Error(), "sdkmanager failed") {
+		t.Fatalf("installAndroidSDK() error = %v", err)
+	}
+	if got := fake.Calls(); len(got) != 1 || got[0] != install {
+		t.Errorf("commands = %q, want %q", got, install)
+	}
+}
+
+// licenseCheckingRunner records whether the SDK licence was accepted
+// by the time sdkmanager runs
+type licenseCheckingRunner struct {
+	*runner.Fake
+	sdk      string
+	accepted bool
+}
+
+func (r *licenseCheckingRunner) RunAttached(ctx context.Context, name string, args ...string) error {
+	_, err := os.Stat(filepath.Join(r.sdk, "licenses", "android-sdk-license"))
+	r.accepted = err == nil
+	return r.Fake.RunAttached(ctx, name, args...)
+}
+
+func TestInstallAndroidSDKAcceptsLicenses(t *testing.T) {
+	server, _ := newAndroidRepositoryServer(t, nil, "")
+	sdk := t.TempDir()
+	sdkmanager := sdkManagerPath(PlatformLinux, sdk)
+	if err := os.MkdirAll(filepath.Dir(sdkmanager), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sdkmanager, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	install := sdkmanager + " --sdk_root=" + sdk + " --install " + strings.Join(DefaultAndroidPackages, " ")
+	r := &licenseCheckingRunner{Fake: runner.NewFake().On(install, runner.Result{}), sdk: sdk}
+	config := &InstallConfig{
+		Platform:              PlatformLinux,
+		AndroidSDKPath:        sdk,
+		AndroidRepositoryURL:  server.URL,
+		AcceptAndroidLicenses: true,
+	}
+
+	if err := installAndroidSDK(context.Background(), r, config, func(int, string) {}); err != nil {
+		t.Fatalf("installAndroidSDK() error = %v", err)
+	}
+	// sdkmanager then finds every licence accepted and does not prompt
+	if !r.accepted {
+		t.Error("licences were not accepted before sdkmanager ran")
+	}
+	if got := r.Calls(); !reflect.DeepEqual(got, []string{install}) {
+		t.Errorf("commands = %q, want %q", got, install)
+	}
+	if !androidsdk.LicenseAccepted(sdk, androidsdk.License{ID: "android-sdk-license", Text: "Terms and Conditions"}) {
+		t.Error("android-sdk-license not accepted")
+	}
+}
+
+func TestAndroidLicenses(t *testing.T) {
+	server, _ := newAndroidRepositoryServer(t, nil, "")
+	sdk := t.TempDir()
+	l := &LinuxInstaller{Config: &InstallConfig{Platform: PlatformLinux, AndroidRepositoryURL: server.URL}, Runner: runner.NewFake()}
+
+	licenses, err := l.AndroidLicenses()
+	if err != nil {
+		t.Fatalf("AndroidLicenses() error = %v", err)
+	}
+	if len(licenses) != 1 || licenses[0].ID != "android-sdk-license" || licenses[0].Text != "Terms and Conditions" {
+		t.Errorf("AndroidLicenses() = %+v", licenses)
+	}
+
+	if err := l.WriteAndroidLicenses(licenses); err == nil {
+		t.Error("WriteAndroidLicenses() succeeded without an SDK path")
+	}
+	l.Config.AndroidSDKPath = sdk
+	if err := l.WriteAndroidLicenses(licenses); err != nil {
+		t.Fatalf("WriteAndroidLicenses() error = %v", err)
+	}
+	if !androidsdk.LicenseAccepted(sdk, licenses[0]) {
+		t.Error("reviewed licence not accepted")
+	}
+	// Licences the manifest does not list were never shown
+	if _, err := os.Stat(filepath.Join(sdk, "licenses", "android-googletv-license")); !os.IsNotExist(err) {
+		t.Error("accepted a licence that was not reviewed")
+	}
+}
+
+func TestSDKManagerPath(t *testing.T) {
+	if got := sdkManagerPath(PlatformWindows, `C:\Sdk`); filepath.Base(got) != "sdkmanager.bat" {
+		t.Errorf("Windows sdkmanager = %q, want sdkmanager.bat", got)
+	}
+}
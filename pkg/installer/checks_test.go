@@ -0,0 +1,378 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+func TestCheckGit(t *testing.T) {
+	tests := []struct {
+		name          string
+		fake          *runner.Fake
+		wantInstalled bool
+		wantVersion   string
+		wantPath      string
+	}{
+		{
+			name: "installed",
+			fake: runner.NewFake().
+				On("git --version", runner.Result{Stdout: "git version 2.45.2\n"}).
+				OnPath("git", "/usr/bin/git"),
+			wantInstalled: true,
+			wantVersion:   "git version 2.45.2",
+			wantPath:      "/usr/bin/git",
+		},
+		{
+			name: "xcode stub without command line tools",
+			fake: runner.NewFake().
+				On("git --version", runner.Result{Stderr: "xcode-select: note: No developer tools were found\n", ExitCode: 1}).
+				OnPath("git", "/usr/bin/git"),
+			wantPath: "/usr/bin/git",
+		},
+		{
+			name: "missing",
+			fake: runner.NewFake(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &InstallConfig{Platform: PlatformLinux}
+			dep := checkGit(context.Background(), tt.fake, config)
+
+			if dep.IsInstalled != tt.wantInstalled || dep.Version != tt.wantVersion || dep.Path != tt.wantPath {
+				t.Errorf("checkGit = installed %v, version %q, path %q; want %v, %q, %q",
+					dep.IsInstalled, dep.Version, dep.Path, tt.wantInstalled, tt.wantVersion, tt.wantPath)
+			}
+			if config.GitPath != tt.wantPath {
+				t.Errorf("config.GitPath = %q, want %q", config.GitPath, tt.wantPath)
+			}
+			if !dep.Required || dep.Hint == "" {
+				t.Errorf("git should be required and come with a hint: %+v", dep)
+			}
+		})
+	}
+}
+
+func TestCheckFlutter(t *testing.T) {
+	sdk := filepath.Join("home", "dev", "flutter")
+
+	tests := []struct {
+		name          string
+		fake          *runner.Fake
+		wantInstalled bool
+		wantVersion   string
+		wantPath      string
+	}{
+		{
+			name: "installed",
+			fake: runner.NewFake().
+				On("flutter --version", runner.Result{Stdout: "Flutter 3.24.5 • channel stable • https://github.com/flutter/flutter.git\n" +
+					"Framework • revision dec2ee5c1f (11 months ago) • 2024-11-13 11:13:06 -0800\n"}).
+				OnPath("flutter", filepath.Join(sdk, "bin", "flutter")),
+			wantInstalled: true,
+			wantVersion:   "Flutter 3.24.5 • channel stable • https://github.com/flutter/flutter.git",
+			wantPath:      sdk,
+		},
+		{
+			name: "on PATH but broken",
+			fake: runner.NewFake().
+				On("flutter --version", runner.Result{Stderr: "Error: Unable to find git in your PATH.\n", ExitCode: 1}).
+				OnPath("flutter", filepath.Join(sdk, "bin", "flutter")),
+			wantPath: sdk,
+		},
+		{
+			name: "missing",
+			fake: runner.NewFake(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &InstallConfig{}
+			dep := checkFlutter(context.Background(), tt.fake, config)
+
+			if dep.IsInstalled != tt.wantInstalled || dep.Version != tt.wantVersion || dep.Path != tt.wantPath {
+				t.Errorf("checkFlutter = installed %v, version %q, path %q; want %v, %q, %q",
+					dep.IsInstalled, dep.Version, dep.Path, tt.wantInstalled, tt.wantVersion, tt.wantPath)
+			}
+			if config.FlutterPath != tt.wantPath {
+				t.Errorf("config.FlutterPath = %q, want %q", config.FlutterPath, tt.wantPath)
+			}
+			if dep.Required {
+				t.Error("Flutter SDK should not be required before installing it")
+			}
+		})
+	}
+}
+
+// newAndroidSDK creates an SDK root containing platform-tools
+func newAndroidSDK(t *testing.T) string {
+	t.Helper()
+	sdk := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(sdk, "platform-tools"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return sdk
+}
+
+func TestCheckAndroidSDKPaths(t *testing.T) {
+	sdk := newAndroidSDK(t)
+	empty := t.TempDir()
+	adb := filepath.Join(sdk, "platform-tools", "adb")
+
+	tests := []struct {
+		name          string
+		paths         []string
+		fake          *runner.Fake
+		wantInstalled bool
+		wantVersion   string
+		wantPath      string
+	}{
+		{
+			name:          "first existing candidate wins",
+			paths:         []string{"", empty, sdk},
+			fake:          runner.NewFake().On(adb+" version", runner.Result{Stdout: "Android Debug Bridge version 1.0.41\nVersion 35.0.1-11580240\n"}),
+			wantInstalled: true,
+			wantVersion:   "Android Debug Bridge version 1.0.41",
+			wantPath:      sdk,
+		},
+		{
+			name:          "adb not runnable",
+			paths:         []string{sdk},
+			fake:          runner.NewFake(),
+			wantInstalled: true,
+			wantPath:      sdk,
+		},
+		{
+			name:  "no candidate has platform-tools",
+			paths: []string{"", empty, filepath.Join(empty, "missing")},
+			fake:  runner.NewFake(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &InstallConfig{}
+			dep := checkAndroidSDKPaths(context.Background(), tt.fake, config, androidRequirements(config, false), tt.paths)
+
+			if dep.IsInstalled != tt.wantInstalled || dep.Version != tt.wantVersion || dep.Path != tt.wantPath {
+				t.Errorf("checkAndroidSDKPaths = installed %v, version %q, path %q; want %v, %q, %q",
+					dep.IsInstalled, dep.Version, dep.Path, tt.wantInstalled, tt.wantVersion, tt.wantPath)
+			}
+			if config.AndroidSDKPath != tt.wantPath {
+				t.Errorf("config.AndroidSDKPath = %q, want %q", config.AndroidSDKPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestWindowsCheckAndroidSDK(t *testing.T) {
+	sdk := newAndroidSDK(t)
+	t.Setenv("ANDROID_HOME", "")
+	t.Setenv("ANDROID_SDK_ROOT", "")
+	t.Setenv("USERPROFILE", t.TempDir())
+	t.Setenv("LOCALAPPDATA", filepath.Dir(filepath.Dir(sdk)))
+
+	// The SDK is only found when it sits at %LOCALAPPDATA%\Android\Sdk
+	w := &WindowsInstaller{Config: &InstallConfig{Platform: PlatformWindows}, Runner: runner.NewFake()}
+	if dep := w.checkAndroidSDK(context.Background()); dep.IsInstalled {
+		t.Errorf("found an SDK at %s", dep.Path)
+	}
+
+	t.Setenv("ANDROID_SDK_ROOT", sdk)
+	adb := filepath.Join(sdk, "platform-tools", "adb.exe")
+	w.Runner = runner.NewFake().On(adb+" version", runner.Result{Stdout: "Android Debug Bridge version 1.0.41\r\n"})
+	dep := w.checkAndroidSDK(context.Background())
+	if !dep.IsInstalled || dep.Path != sdk || dep.Version != "Android Debug Bridge version 1.0.41" {
+		t.Errorf("checkAndroidSDK = %+v, want the SDK from ANDROID_SDK_ROOT", dep)
+	}
+}
+
+func TestWindowsCheckVisualStudio(t *testing.T) {
+	t.Setenv("ProgramFiles(x86)", t.TempDir())
+	vswhere := filepath.Join(os.Getenv("ProgramFiles(x86)"), "Microsoft Visual Studio", "Installer", "vswhere.exe") +
+		" -latest -products * -requires Microsoft.VisualStudio.Component.VC.Tools.x86.x64 -format json"
+
+	tests := []struct {
+		name   string
+		fake   *runner.Fake
+		want   bool
+		target TargetPlatform
+	}{
+		{
+			name: "c++ workload",
+			fake: runner.NewFake().On(vswhere, runner.Result{
+				Stdout: `[{"displayName": "Visual Studio Community 2022", "installationPath": "C:\\Program Files\\Microsoft Visual Studio\\2022\\Community"}]`,
+			}),
+			want:   true,
+			target: TargetDesktop,
+		},
+		// vswhere prints an empty list when no instance has the workload
+		{name: "without c++ workload", fake: runner.NewFake().On(vswhere, runner.Result{Stdout: "[]"}), target: TargetDesktop},
+		{name: "no visual studio", fake: runner.NewFake(), target: TargetAndroid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WindowsInstaller{Config: &InstallConfig{Platform: PlatformWindows, Target: tt.target}, Runner: tt.fake}
+			dep := w.checkVisualStudio(context.Background())
+			if dep.IsInstalled != tt.want {
+				t.Errorf("checkVisualStudio = %+v, want installed %v", dep, tt.want)
+			}
+			if tt.want && (dep.Version != "Visual Studio Community 2022" || !strings.HasSuffix(dep.Path, "Community")) {
+				t.Errorf("checkVisualStudio = version %q, path %q", dep.Version, dep.Path)
+			}
+			if dep.Required != (tt.target == TargetDesktop) {
+				t.Errorf("required = %v for target %q", dep.Required, tt.target)
+			}
+		})
+	}
+}
+
+func TestLinuxCheckDesktopToolchain(t *testing.T) {
+	allTools := func() *runner.Fake {
+		return runner.NewFake().
+			OnPath("clang++", "/usr/bin/clang++").
+			OnPath("cmake", "/usr/bin/cmake").
+			OnPath("ninja", "/usr/bin/ninja").
+			OnPath("pkg-config", "/usr/bin/pkg-config")
+	}
+
+	tests := []struct {
+		name          string
+		fake          *runner.Fake
+		target        TargetPlatform
+		wantInstalled bool
+		wantVersion   string
+		wantHint      string
+	}{
+		{
+			name:          "complete",
+			fake:          allTools().On("pkg-config --modversion gtk+-3.0", runner.Result{Stdout: "3.24.41\n"}),
+			target:        TargetDesktop,
+			wantInstalled: true,
+			wantVersion:   "clang++, cmake, ninja, pkg-config, gtk+-3.0 3.24.41",
+			wantHint:      "sudo apt-get install clang cmake ninja-build pkg-config libgtk-3-dev",
+		},
+		{
+			name: "missing gtk headers",
+			fake: allTools().On("pkg-config --modversion gtk+-3.0", runner.Result{
+				Stderr:   "Package gtk+-3.0 was not found in the pkg-config search path.\n",
+				ExitCode: 1,
+			}),
+			target:   TargetDesktop,
+			wantHint: "sudo apt-get install libgtk-3-dev",
+		},
+		{
+			name:     "nothing installed",
+			fake:     runner.NewFake(),
+			target:   TargetAndroid,
+			wantHint: "sudo apt-get install clang cmake ninja-build pkg-config libgtk-3-dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &LinuxInstaller{Config: &InstallConfig{Target: tt.target}, Runner: tt.fake}
+			dep := l.checkDesktopToolchain(context.Background())
+
+			if dep.IsInstalled != tt.wantInstalled || dep.Version != tt.wantVersion || dep.Hint != tt.wantHint {
+				t.Errorf("checkDesktopToolchain = installed %v, version %q, hint %q; want %v, %q, %q",
+					dep.IsInstalled, dep.Version, dep.Hint, tt.wantInstalled, tt.wantVersion, tt.wantHint)
+			}
+			if dep.Required != (tt.target == TargetDesktop) {
+				t.Errorf("required = %v for target %q", dep.Required, tt.target)
+			}
+		})
+	}
+}
+
+func TestRunFlutterDoctor(t *testing.T) {
+	fake := runner.NewFake().On("flutter doctor -v", runner.Result{
+		Stdout:   "[!] Android toolchain - develop for Android devices\n",
+		ExitCode: 1,
+	})
+	l := &LinuxInstaller{Config: &InstallConfig{}, Runner: fake}
+
+	output, err := l.RunFlutterDoctor(context.Background())
+	if err == nil {
+		t.Error("a non-zero exit should be reported")
+	}
+	if output != "[!] Android toolchain - develop for Android devices\n" {
+		t.Errorf("output = %q", output)
+	}
+
+	if err := l.AcceptAndroidLicenses(context.Background()); err == nil {
+		t.Error("accepting licenses without flutter should fail")
+	}
+	if calls := fake.Calls(); len(calls) != 2 || calls[1] != "flutter doctor --android-licenses" {
+		t.Errorf("calls = %q", calls)
+	}
+}
+
+func TestCheckFlutterTimeout(t *testing.T) {
+	// The first flutter command downloads the Dart SDK and may not finish
+	fake := runner.NewFake().
+		On("flutter --version", runner.Result{Stdout: "Flutter 3.24.5\n"}).
+		Delay("flutter --version", time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	dep := checkFlutter(ctx, fake, &InstallConfig{})
+	if dep.IsInstalled {
+		t.Error("a probe that timed out reported Flutter as installed")
+	}
+	if !strings.Contains(dep.Hint, "did not finish") {
+		t.Errorf("hint = %q, want it to explain the timeout", dep.Hint)
+	}
+}
+
+func TestCheckAndroidSDKComponents(t *testing.T) {
+	sdk := t.TempDir()
+	for path, revision := range map[string]string{
+		"platform-tools":       "<major>35</major>",
+		"cmdline-tools;latest": "<major>13</major>",
+		"build-tools;30.0.3":   "<major>30</major><minor>0</minor><micro>3</micro>",
+		"platforms;android-34": "<major>2</major>",
+	} {
+		dir := filepath.Join(sdk, filepath.FromSlash(strings.ReplaceAll(path, ";", "/")))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		xml := `<repository><localPackage path="` + path + `"><revision>` + revision + `</revision></localPackage></repository>`
+		if err := os.WriteFile(filepath.Join(dir, "package.xml"), []byte(xml), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &InstallConfig{Platform: PlatformLinux, FlutterVersion: "3.24.5"}
+	dep := checkAndroidSDKPaths(context.Background(), runner.NewFake(), config, androidRequirements(config, false), []string{sdk})
+	if !dep.IsInstalled {
+		t.Fatal("an SDK with platform-tools should count as installed")
+	}
+	if dep.Problem != "build-tools 30.0.3 is too old; Flutter needs 34.0.0 or newer" {
+		t.Errorf("problem = %q", dep.Problem)
+	}
+	if want := "Run: flutter-takeoff android-sdk --path '" + sdk + "' --packages 'build-tools;34.0.0'"; dep.Hint != want {
+		t.Errorf("hint = %q, want %q", dep.Hint, want)
+	}
+	// cmd.exe only understands double quotes, and needs no escaped backslashes
+	config.Platform = PlatformWindows
+	if dep := checkAndroidSDKPaths(context.Background(), runner.NewFake(), config, androidRequirements(config, false), []string{sdk}); !strings.HasSuffix(dep.Hint, `--path "`+sdk+`" --packages "build-tools;34.0.0"`) {
+		t.Errorf("Windows hint = %q", dep.Hint)
+	}
+	config.Platform = PlatformLinux
+
+	// Flutter 3.27 compiles against android-35
+	config.FlutterVersion = "3.27.x"
+	if dep := checkAndroidSDKPaths(context.Background(), runner.NewFake(), config, androidRequirements(config, false), []string{sdk}); !strings.Contains(dep.Problem, "Newest platform is android-34") {
+		t.Errorf("problem for 3.27 = %q", dep.Problem)
+	}
+}
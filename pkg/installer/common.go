@@ -0,0 +1,366 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/appdirs"
+	"flutter_takeoff/pkg/archive"
+	"flutter_takeoff/pkg/download"
+	"flutter_takeoff/pkg/releases"
+	"flutter_takeoff/pkg/runner"
+)
+
+// Dependency checks and commands shared by every platform installer
+
+const (
+	// DefaultStorageBaseURL is the host official Flutter releases are served from
+	DefaultStorageBaseURL = "https://storage.googleapis.com"
+	// DefaultFlutterVersion is installed when InstallConfig.FlutterVersion is empty
+	DefaultFlutterVersion = "latest"
+	// DefaultChannel is used when InstallConfig.Channel is empty
+	DefaultChannel = "stable"
+)
+
+// Timeouts for external commands. The first flutter command after an
+// install downloads the Dart SDK, which can take minutes
+const (
+	probeTimeout   = 20 * time.Second
+	flutterTimeout = 5 * time.Minute
+	doctorTimeout  = 10 * time.Minute
+)
+
+// run runs a command, killing it when timeout passes or ctx is done
+func run(ctx context.Context, r runner.CommandRunner, timeout time.Duration, name string, args ...string) (runner.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return r.Run(ctx, name, args...)
+}
+
+func checkGit(ctx context.Context, r runner.CommandRunner, config *InstallConfig) Dependency {
+	dep := Dependency{
+		Name:        "Git",
+		Description: "Version control system (required for Flutter)",
+		Required:    true,
+		Hint:        gitHint(config.Platform),
+	}
+
+	if result, err := run(ctx, r, probeTimeout, "git", "--version"); err == nil {
+		dep.IsInstalled = true
+		dep.Version = strings.TrimSpace(result.Combined())
+	}
+
+	// Try to find git path
+	gitPath, err := r.LookPath("git")
+	if err == nil {
+		config.GitPath = gitPath
+		dep.Path = gitPath
+	}
+
+	return dep
+}
+
+func checkFlutter(ctx context.Context, r runner.CommandRunner, config *InstallConfig) Dependency {
+	dep := Dependency{
+		Name:        "Flutter SDK",
+		Description: "Flutter development framework",
+		Required:    false,
+		Hint:        "Run 'flutter-takeoff install' to download the Flutter SDK",
+	}
+
+	result, err := run(ctx, r, flutterTimeout, "flutter", "--version")
+	if err == nil {
+		dep.IsInstalled = true
+		lines := strings.Split(result.Combined(), "\n")
+		if len(lines) > 0 {
+			dep.Version = strings.TrimSpace(lines[0])
+		}
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		dep.Hint = "'flutter --version' did not finish in time; run it once in a terminal to let it finish setting up"
+	}
+
+	// Try to find flutter path
+	flutterPath, err := r.LookPath("flutter")
+	if err == nil {
+		config.FlutterPath = filepath.Dir(filepath.Dir(flutterPath))
+		dep.Path = config.FlutterPath
+	}
+
+	return dep
+}
+
+// executable returns the file name of a command on platform, e.g.
+// java.exe on Windows
+func executable(platform Platform, name string) string {
+	if platform == PlatformWindows {
+		return name + ".exe"
+	}
+	return name
+}
+
+// goos returns the runtime.GOOS value of platform
+func goos(platform Platform) string {
+	if platform == PlatformMacOS {
+		return "darwin"
+	}
+	return string(platform)
+}
+
+// gitHint returns the usual way to install git on platform
+func gitHint(platform Platform) string {
+	switch platform {
+	case PlatformWindows:
+		return "Install Git from https://git-scm.com/download/win or run: winget install Git.Git"
+	case PlatformMacOS:
+		return "Install the Xcode command line tools, which include git: xcode-select --install"
+	case PlatformLinux:
+		return "Install git with your package manager, e.g. sudo apt-get install git"
+	default:
+		return "Install Git from https://git-scm.com/downloads"
+	}
+}
+
+// checkAndroidSDKPaths looks for platform-tools in each candidate SDK root,
+// then checks the components installed there against what the configured
+// Flutter version needs
+func checkAndroidSDKPaths(ctx context.Context, r runner.CommandRunner, config *InstallConfig, req androidsdk.Requirements, possiblePaths []string) Dependency {
+	dep := Dependency{
+		Name:        "Android SDK",
+		Description: "Android command-line tools (required for Android development)",
+		Required:    true,
+		Hint:        "Run 'flutter-takeoff android-sdk' to install the command-line tools and SDK packages, install Android Studio from https://developer.android.com/studio, or set ANDROID_HOME to an existing SDK",
+	}
+
+	for _, path := range possiblePaths {
+		if path != "" {
+			if _, err := os.Stat(filepath.Join(path, "platform-tools")); err == nil {
+				dep.IsInstalled = true
+				dep.Path = path
+				config.AndroidSDKPath = path
+
+				// Try to get version
+				adbPath := filepath.Join(path, "platform-tools", executable(config.Platform, "adb"))
+				if result, err := run(ctx, r, probeTimeout, adbPath, "version"); err == nil {
+					dep.Version = strings.TrimSpace(strings.Split(result.Combined(), "\n")[0])
+				}
+				break
+			}
+		}
+	}
+	if !dep.IsInstalled {
+		return dep
+	}
+
+	inv, err := androidsdk.ReadInventory(dep.Path)
+	if err != nil {
+		return dep
+	}
+	var problems, packages []string
+	for _, issue := range inv.Check(req) {
+		if issue.Required {
+			problems = append(problems, issue.Message)
+			packages = append(packages, issue.Package)
+		}
+	}
+	if len(problems) > 0 {
+		dep.Problem = strings.Join(problems, "; ")
+		dep.Hint = fmt.Sprintf("Run: flutter-takeoff android-sdk --path %s --packages %s",
+			commandQuote(config.Platform, dep.Path), commandQuote(config.Platform, strings.Join(packages, ",")))
+	}
+	return dep
+}
+
+// androidRequirements returns the SDK components the configured Flutter
+// version needs, suggesting arm64 system images on arm64 machines
+func androidRequirements(config *InstallConfig, arm64 bool) androidsdk.Requirements {
+	req := androidsdk.RequirementsFor(config.FlutterVersion)
+	if arm64 {
+		req.SystemImageABI = "arm64-v8a"
+	}
+	return req
+}
+
+// androidSDKInventory lists the components of config.AndroidSDKPath, or
+// of the first candidate SDK root that exists
+func androidSDKInventory(config *InstallConfig, possiblePaths []string) (*androidsdk.Inventory, error) {
+	if config.AndroidSDKPath != "" {
+		return androidsdk.ReadInventory(config.AndroidSDKPath)
+	}
+	for _, path := range possiblePaths {
+		if info, err := os.Stat(path); path != "" && err == nil && info.IsDir() {
+			config.AndroidSDKPath = path
+			return androidsdk.ReadInventory(path)
+		}
+	}
+	return nil, errors.New("no Android SDK found; set ANDROID_HOME or install one with 'flutter-takeoff android-sdk'")
+}
+
+// defaultUnixFlutterPath returns ~/development/flutter, as in the Flutter
+// docs, when that folder exists and ~/flutter otherwise
+func defaultUnixFlutterPath() string {
+	home, _ := os.UserHomeDir()
+
+	if info, err := os.Stat(filepath.Join(home, "development")); err == nil && info.IsDir() {
+		return filepath.Join(home, "development", "flutter")
+	}
+	return filepath.Join(home, "flutter")
+}
+
+// releaseVersion returns the configured Flutter version and channel
+func releaseVersion(config *InstallConfig) (string, string) {
+	version, channel := config.FlutterVersion, config.Channel
+	if version == "" {
+		version = DefaultFlutterVersion
+	}
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return version, channel
+}
+
+// releaseURL returns the download URL of a release archive, given its
+// path relative to the releases folder
+func releaseURL(config *InstallConfig, archive string) string {
+	base := config.StorageBaseURL
+	if base == "" {
+		base = os.Getenv("FLUTTER_STORAGE_BASE_URL")
+	}
+	if base == "" {
+		base = DefaultStorageBaseURL
+	}
+	return strings.TrimRight(base, "/") + "/flutter_infra_release/releases/" + archive
+}
+
+// ResolveRelease looks up the configured version and channel in the
+// releases manifest for osName ("windows", "macos" or "linux") and arch
+func ResolveRelease(config *InstallConfig, osName, arch string) (*releases.Release, error) {
+	manifest, err := releases.Fetch(http.DefaultClient, releaseURL(config, releases.ManifestName(osName)))
+	if err != nil {
+		return nil, err
+	}
+
+	version, channel := releaseVersion(config)
+	return manifest.Resolve(version, channel, arch)
+}
+
+// downloadDir returns where SDK archives are cached between runs
+func downloadDir(config *InstallConfig) (string, error) {
+	if config.DownloadDir != "" {
+		return config.DownloadDir, nil
+	}
+	cache, err := appdirs.CacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cache, "downloads"), nil
+}
+
+// isFlutterSDK reports whether path holds a Flutter SDK
+func isFlutterSDK(path string) bool {
+	for _, name := range []string{"flutter", "flutter.bat"} {
+		if _, err := os.Stat(filepath.Join(path, "bin", name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInstallPath makes sure installing into path cannot clobber
+// anything but an earlier Flutter SDK
+func checkInstallPath(path string) error {
+	if path == "" {
+		return errors.New("no Flutter installation path set")
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil || len(entries) == 0 || isFlutterSDK(path) {
+		return nil
+	}
+	return fmt.Errorf("%s is not empty and does not contain a Flutter SDK", path)
+}
+
+// downloadFlutterRelease resolves the configured release, downloads its
+// archive into the download cache and extracts it into
+// config.FlutterPath. An interrupted download is resumed and an archive
+// that is already cached and verified is reused
+func downloadFlutterRelease(ctx context.Context, config *InstallConfig, osName, arch string, progressCallback func(percent int, status string)) error {
+	if err := checkInstallPath(config.FlutterPath); err != nil {
+		return err
+	}
+
+	progressCallback(0, "Resolving Flutter release...")
+
+	release, err := ResolveRelease(config, osName, arch)
+	if err != nil {
+		return fmt.Errorf("failed to resolve Flutter release: %w", err)
+	}
+	if release.SHA256 == "" {
+		return fmt.Errorf("releases manifest has no sha256 for %s", release.Archive)
+	}
+
+	dir, err := downloadDir(config)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, path.Base(release.Archive))
+
+	if download.VerifySHA256(dest, release.SHA256) == nil {
+		progressCallback(100, fmt.Sprintf("Using cached Flutter %s (%s)", release.Version, release.Channel))
+		config.ArchivePath = dest
+		return extractFlutterArchive(ctx, config, progressCallback)
+	}
+
+	progressCallback(0, fmt.Sprintf("Preparing to download Flutter %s (%s)...", release.Version, release.Channel))
+
+	url := releaseURL(config, release.Archive)
+	if err := download.New().Download(ctx, url, dest, progressCallback); err != nil {
+		return fmt.Errorf("failed to download Flutter SDK: %w", err)
+	}
+
+	// A truncated or tampered archive must never reach extraction
+	progressCallback(100, "Verifying checksum...")
+	if err := download.VerifySHA256(dest, release.SHA256); err != nil {
+		os.Remove(dest)
+		var checksumErr *download.ChecksumError
+		if errors.As(err, &checksumErr) {
+			return fmt.Errorf("downloaded Flutter SDK is corrupt (sha256 %s, expected %s); the file was deleted, please retry",
+				checksumErr.Actual, checksumErr.Expected)
+		}
+		return fmt.Errorf("failed to verify Flutter SDK: %w", err)
+	}
+	config.ArchivePath = dest
+
+	return extractFlutterArchive(ctx, config, progressCallback)
+}
+
+// extractFlutterArchive unpacks config.ArchivePath into config.FlutterPath,
+// replacing an earlier SDK there only once extraction has succeeded
+func extractFlutterArchive(ctx context.Context, config *InstallConfig, progressCallback func(percent int, status string)) error {
+	progressCallback(0, "Extracting Flutter SDK...")
+
+	extractor := &archive.Extractor{Overwrite: isFlutterSDK(config.FlutterPath)}
+	if err := extractor.Extract(ctx, config.ArchivePath, config.FlutterPath, progressCallback); err != nil {
+		return fmt.Errorf("failed to extract Flutter SDK: %w", err)
+	}
+
+	progressCallback(100, "Flutter SDK installed to "+config.FlutterPath)
+	return nil
+}
+
+// acceptAndroidLicenses lets the user answer the licence prompts, so
+// only ctx limits how long it runs
+func acceptAndroidLicenses(ctx context.Context, r runner.CommandRunner) error {
+	return r.RunAttached(ctx, "flutter", "doctor", "--android-licenses")
+}
+
+func runFlutterDoctor(ctx context.Context, r runner.CommandRunner) (string, error) {
+	result, err := run(ctx, r, doctorTimeout, "flutter", "doctor", "-v")
+	return result.Combined(), err
+}
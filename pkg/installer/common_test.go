@@ -0,0 +1,188 @@
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+const testManifest = `{
+  "base_url": "https://storage.googleapis.com/flutter_infra_release/releases",
+  "current_release": {"stable": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668"},
+  "releases": [
+    {
+      "hash": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668",
+      "channel": "stable",
+      "version": "3.24.5",
+      "dart_sdk_version": "3.5.4",
+      "dart_sdk_arch": "x64",
+      "release_date": "2024-11-20T21:08:38.063466Z",
+      "archive": "stable/linux/flutter_linux_3.24.5-stable.tar.xz",
+      "sha256": "%s"
+    }
+  ]
+}`
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// newReleaseServer serves a one-release Linux manifest and its archive
+// under the same paths as the official storage bucket
+func newReleaseServer(t *testing.T, archive []byte, checksum string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/flutter_infra_release/releases/releases_linux.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, testManifest, checksum)
+	})
+	mux.HandleFunc("/flutter_infra_release/releases/stable/linux/flutter_linux_3.24.5-stable.tar.xz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testSDKArchive builds a minimal Flutter tar.xz archive
+func testSDKArchive(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	xw, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(xw)
+	for name, body := range map[string]string{
+		"flutter/bin/flutter": "#!/bin/sh\n",
+		"flutter/version":     "3.24.5\n",
+	} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(body)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(body))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownloadFlutterFromStorageBaseURL(t *testing.T) {
+	archive := testSDKArchive(t)
+	server := newReleaseServer(t, archive, sha256Hex(archive))
+
+	flutterPath := filepath.Join(t.TempDir(), "flutter")
+	config := &InstallConfig{
+		Platform:       PlatformLinux,
+		FlutterPath:    flutterPath,
+		StorageBaseURL: server.URL,
+		DownloadDir:    t.TempDir(),
+	}
+	var last int
+	err := NewLinuxInstaller(config).DownloadFlutter(context.Background(), func(percent int, status string) {
+		last = percent
+	})
+	if err != nil {
+		t.Fatalf("DownloadFlutter() error = %v", err)
+	}
+	if last != 100 {
+		t.Errorf("last progress = %d, want 100", last)
+	}
+
+	got, err := os.ReadFile(config.ArchivePath)
+	if err != nil {
+		t.Fatalf("archive not saved: %v", err)
+	}
+	if !bytes.Equal(got, archive) {
+		t.Errorf("cached archive differs from the served one")
+	}
+
+	if !isFlutterSDK(flutterPath) {
+		t.Errorf("%s does not contain the extracted SDK", flutterPath)
+	}
+}
+
+func TestDownloadFlutterRefusesNonSDKDirectory(t *testing.T) {
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, "notes.txt"), []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// No server: the path must be rejected before anything is downloaded
+	config := &InstallConfig{FlutterPath: home, StorageBaseURL: "http://127.0.0.1:0"}
+	err := NewLinuxInstaller(config).DownloadFlutter(context.Background(), func(int, string) {})
+	if err == nil || !strings.Contains(err.Error(), "does not contain a Flutter SDK") {
+		t.Fatalf("DownloadFlutter() error = %v", err)
+	}
+}
+
+func TestDownloadFlutterReusesCachedArchive(t *testing.T) {
+	archive := testSDKArchive(t)
+	server := newReleaseServer(t, nil, sha256Hex(archive))
+
+	dir := t.TempDir()
+	cached := filepath.Join(dir, "flutter_linux_3.24.5-stable.tar.xz")
+	if err := os.WriteFile(cached, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server would serve an empty archive, so a download would fail verification
+	config := &InstallConfig{FlutterPath: filepath.Join(t.TempDir(), "flutter"), StorageBaseURL: server.URL, DownloadDir: dir}
+	if err := NewLinuxInstaller(config).DownloadFlutter(context.Background(), func(int, string) {}); err != nil {
+		t.Fatalf("DownloadFlutter() error = %v", err)
+	}
+	if config.ArchivePath != cached {
+		t.Errorf("ArchivePath = %q, want %q", config.ArchivePath, cached)
+	}
+}
+
+func TestDownloadFlutterChecksumMismatch(t *testing.T) {
+	tmp := t.TempDir()
+
+	// The manifest describes the full archive but the proxy cut it short
+	archive := []byte("not really an archive")
+	server := newReleaseServer(t, archive[:8], sha256Hex(archive))
+
+	config := &InstallConfig{FlutterPath: filepath.Join(t.TempDir(), "flutter"), StorageBaseURL: server.URL, DownloadDir: tmp}
+	err := NewLinuxInstaller(config).DownloadFlutter(context.Background(), func(int, string) {})
+	if err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("DownloadFlutter() error = %v, want corrupt archive", err)
+	}
+	if config.ArchivePath != "" {
+		t.Errorf("ArchivePath = %q, want empty", config.ArchivePath)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("corrupt archive left behind: %v", entries)
+	}
+}
+
+func TestDownloadFlutterUnknownVersion(t *testing.T) {
+	server := newReleaseServer(t, nil, "")
+
+	config := &InstallConfig{
+		FlutterPath:    filepath.Join(t.TempDir(), "flutter"),
+		StorageBaseURL: server.URL,
+		DownloadDir:    t.TempDir(),
+		FlutterVersion: "2.0.0",
+	}
+	err := NewLinuxInstaller(config).DownloadFlutter(context.Background(), func(int, string) {})
+	if err == nil {
+		t.Fatal("DownloadFlutter() of an unknown version should fail")
+	}
+}
@@ -0,0 +1,285 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+// avdManagerTimeout bounds an avdmanager run, which starts a JVM and
+// can take a while to load the SDK
+const avdManagerTimeout = 2 * time.Minute
+
+// AVD is an Android Virtual Device as listed by avdmanager
+type AVD struct {
+	Name   string `json:"name"`
+	Device string `json:"device"`
+	Path   string `json:"path"`
+	Target string `json:"target"`
+	// ABI is the system image's tag and ABI, e.g. "google_apis/x86_64"
+	ABI string `json:"abi"`
+	// Error says why avdmanager could not load the AVD, e.g. because its
+	// system image was uninstalled
+	Error string `json:"error,omitempty"`
+}
+
+// Acceleration reports whether the emulator can use the CPU's
+// virtualization extensions, without which it is too slow to use
+type Acceleration struct {
+	Available bool `json:"available"`
+	// Hypervisor is e.g. "KVM", "WHPX", "HAXM" or "Hypervisor.Framework"
+	Hypervisor string `json:"hypervisor"`
+	Detail     string `json:"detail"`
+}
+
+// Emulator manages AVDs with the avdmanager and emulator of the SDK in
+// Config.AndroidSDKPath
+type Emulator struct {
+	Config *InstallConfig
+	Runner runner.CommandRunner
+}
+
+// avdManager returns the path of avdmanager, which comes with the
+// command-line tools
+func (e *Emulator) avdManager() (string, error) {
+	if e.Config.AndroidSDKPath == "" {
+		return "", errors.New("no Android SDK found; install one with 'flutter-takeoff android-sdk'")
+	}
+	name := "avdmanager"
+	if e.Config.Platform == PlatformWindows {
+		name = "avdmanager.bat"
+	}
+	path := filepath.Join(e.Config.AndroidSDKPath, "cmdline-tools", "latest", "bin", name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("avdmanager not found in %s; install the command-line tools with 'flutter-takeoff android-sdk --packages \"cmdline-tools;latest\"'", e.Config.AndroidSDKPath)
+	}
+	return path, nil
+}
+
+// emulatorPath returns the path of the emulator, or "" when the
+// emulator package is not installed
+func (e *Emulator) emulatorPath() string {
+	if e.Config.AndroidSDKPath == "" {
+		return ""
+	}
+	path := filepath.Join(e.Config.AndroidSDKPath, "emulator", executable(e.Config.Platform, "emulator"))
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// ListAVDs returns the AVDs avdmanager knows about, including the ones
+// it could not load
+func (e *Emulator) ListAVDs(ctx context.Context) ([]AVD, error) {
+	avdmanager, err := e.avdManager()
+	if err != nil {
+		return nil, err
+	}
+	result, err := run(ctx, e.Runner, avdManagerTimeout, avdmanager, "list", "avd")
+	if err != nil {
+		return nil, fmt.Errorf("avdmanager list avd failed: %w", err)
+	}
+	return parseAVDList(result.Stdout), nil
+}
+
+// ListDevices returns the IDs of the device profiles an AVD can be
+// created from, e.g. "pixel_7"
+func (e *Emulator) ListDevices(ctx context.Context) ([]string, error) {
+	avdmanager, err := e.avdManager()
+	if err != nil {
+		return nil, err
+	}
+	result, err := run(ctx, e.Runner, avdManagerTimeout, avdmanager, "list", "device", "-c")
+	if err != nil {
+		return nil, fmt.Errorf("avdmanager list device failed: %w", err)
+	}
+
+	// Warnings about the SDK come before the IDs; IDs have no spaces
+	var devices []string
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.ContainsAny(line, " \t:") {
+			devices = append(devices, line)
+		}
+	}
+	return devices, nil
+}
+
+// CreateAVD creates an AVD named name from a device profile and an
+// installed system image package, e.g.
+// "system-images;android-34;google_apis;x86_64"
+func (e *Emulator) CreateAVD(ctx context.Context, name, device, image string) error {
+	if !validAVDName.MatchString(name) {
+		return fmt.Errorf("invalid AVD name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	avdmanager, err := e.avdManager()
+	if err != nil {
+		return err
+	}
+
+	// avdmanager asks whether to create a custom hardware profile; with
+	// no stdin it takes the default, no
+	_, err = run(ctx, e.Runner, avdManagerTimeout, avdmanager,
+		"create", "avd", "--name", name, "--package", image, "--device", device)
+	if err != nil {
+		return fmt.Errorf("avdmanager create avd failed: %w", err)
+	}
+	return nil
+}
+
+var validAVDName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// CheckAcceleration asks the emulator whether hardware acceleration works.
+// Without the emulator it checks what the platform offers: /dev/kvm on
+// Linux and the Hypervisor framework on macOS
+func (e *Emulator) CheckAcceleration(ctx context.Context) Acceleration {
+	if emulator := e.emulatorPath(); emulator != "" {
+		// -accel-check exits non-zero when acceleration is unusable, but
+		// still explains why
+		result, err := run(ctx, e.Runner, probeTimeout, emulator, "-accel-check")
+		if accel, ok := parseAccelCheck(result.Stdout); ok {
+			return accel
+		}
+		if err != nil {
+			return Acceleration{Hypervisor: defaultHypervisor(e.Config.Platform), Detail: "emulator -accel-check failed: " + err.Error()}
+		}
+	}
+
+	switch e.Config.Platform {
+	case PlatformLinux:
+		accel := Acceleration{Hypervisor: "KVM"}
+		if f, err := os.OpenFile("/dev/kvm", os.O_RDWR, 0); err == nil {
+			f.Close()
+			accel.Available = true
+			accel.Detail = "/dev/kvm is usable"
+		} else if os.IsNotExist(err) {
+			accel.Detail = "/dev/kvm is not found: enable virtualization in the BIOS and load the kvm module"
+		} else {
+			accel.Detail = "/dev/kvm is not accessible: add yourself to the kvm group"
+		}
+		return accel
+	case PlatformMacOS:
+		result, err := run(ctx, e.Runner, probeTimeout, "sysctl", "-n", "kern.hv_support")
+		if err == nil && strings.TrimSpace(result.Stdout) == "1" {
+			return Acceleration{Available: true, Hypervisor: "Hypervisor.Framework", Detail: "Hypervisor.Framework is supported"}
+		}
+		return Acceleration{Hypervisor: "Hypervisor.Framework", Detail: "This Mac does not support Hypervisor.Framework"}
+	default:
+		return Acceleration{Hypervisor: defaultHypervisor(e.Config.Platform), Detail: "Install the emulator to check for WHPX or HAXM: flutter-takeoff android-sdk --packages emulator"}
+	}
+}
+
+func defaultHypervisor(platform Platform) string {
+	switch platform {
+	case PlatformLinux:
+		return "KVM"
+	case PlatformMacOS:
+		return "Hypervisor.Framework"
+	default:
+		return "WHPX"
+	}
+}
+
+// parseAccelCheck reads the status block emulator -accel-check prints:
+//
+//	accel:
+//	0
+//	KVM (version 12) is installed and usable.
+//	accel
+//
+// where 0 means acceleration works and anything else is an error code
+func parseAccelCheck(output string) (Acceleration, bool) {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "accel:" {
+			start = i
+			break
+		}
+	}
+	if start < 0 || start+1 >= len(lines) {
+		return Acceleration{}, false
+	}
+
+	var detail []string
+	for _, line := range lines[start+2:] {
+		if line = strings.TrimSpace(line); line == "accel" {
+			break
+		}
+		if line != "" {
+			detail = append(detail, line)
+		}
+	}
+
+	accel := Acceleration{
+		Available: strings.TrimSpace(lines[start+1]) == "0",
+		Detail:    strings.Join(detail, " "),
+	}
+	for _, name := range []string{"KVM", "WHPX", "HAXM", "AEHD", "Hypervisor.Framework", "HVF"} {
+		if strings.Contains(accel.Detail, name) {
+			accel.Hypervisor = name
+			break
+		}
+	}
+	return accel, true
+}
+
+// parseAVDList reads the output of avdmanager list avd. Each AVD is a
+// block of "Key: value" lines, separated by dashes; AVDs that could not
+// be loaded follow a header and have an Error line
+func parseAVDList(output string) []AVD {
+	var avds []AVD
+	var current *AVD
+	flush := func() {
+		if current != nil && current.Name != "" {
+			avds = append(avds, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "---") || strings.HasPrefix(trimmed, "The following Android Virtual Devices could not be loaded") {
+			flush()
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if key == "Name" {
+			flush()
+			current = &AVD{Name: value}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "Device":
+			current.Device = value
+		case "Path":
+			current.Path = value
+		case "Target":
+			current.Target = value
+		case "Based on":
+			if _, abi, ok := strings.Cut(value, "Tag/ABI:"); ok {
+				current.ABI = strings.TrimSpace(abi)
+			}
+		case "Error":
+			current.Error = value
+		}
+	}
+	flush()
+	return avds
+}
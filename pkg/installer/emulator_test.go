@@ -0,0 +1,216 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+const testAVDList = `Available Android Virtual Devices:
+    Name: Pixel_7_API_34
+  Device: pixel_7 (Google)
+    Path: /home/dev/.android/avd/Pixel_7_API_34.avd
+  Target: Google APIs (Google Inc.)
+          Based on: Android 14.0 ("UpsideDownCake") Tag/ABI: google_apis/x86_64
+  Sdcard: 512 MB
+---------
+    Name: Small_Phone
+    Path: /home/dev/.android/avd/Small_Phone.avd
+  Target: Default Android System Image
+          Based on: Android 13.0 ("Tiramisu") Tag/ABI: default/x86_64
+
+The following Android Virtual Devices could not be loaded:
+    Name: Old_Tablet
+    Path: /home/dev/.android/avd/Old_Tablet.avd
+   Error: Missing system image for Google APIs x86 Old_Tablet.
+`
+
+// newTestEmulatorSDK creates an SDK root with avdmanager and, when
+// withEmulator is set, the emulator, named as on platform
+func newTestEmulatorSDK(t *testing.T, platform Platform, withEmulator bool) (root, avdmanager, emulator string) {
+	t.Helper()
+
+	root = t.TempDir()
+	avdmanager = filepath.Join(root, "cmdline-tools", "latest", "bin", "avdmanager")
+	if platform == PlatformWindows {
+		avdmanager += ".bat"
+	}
+	files := []string{avdmanager}
+	if withEmulator {
+		emulator = filepath.Join(root, "emulator", executable(platform, "emulator"))
+		files = append(files, emulator)
+	}
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, avdmanager, emulator
+}
+
+func TestEmulatorListAVDs(t *testing.T) {
+	root, avdmanager, _ := newTestEmulatorSDK(t, PlatformLinux, false)
+	fake := runner.NewFake().On(avdmanager+" list avd", runner.Result{Stdout: testAVDList})
+	e := &Emulator{Config: &InstallConfig{Platform: PlatformLinux, AndroidSDKPath: root}, Runner: fake}
+
+	avds, err := e.ListAVDs(context.Background())
+	if err != nil {
+		t.Fatalf("ListAVDs() error = %v", err)
+	}
+	want := []AVD{
+		{
+			Name:   "Pixel_7_API_34",
+			Device: "pixel_7 (Google)",
+			Path:   "/home/dev/.android/avd/Pixel_7_API_34.avd",
+			Target: "Google APIs (Google Inc.)",
+			ABI:    "google_apis/x86_64",
+		},
+		{
+			Name:   "Small_Phone",
+			Path:   "/home/dev/.android/avd/Small_Phone.avd",
+			Target: "Default Android System Image",
+			ABI:    "default/x86_64",
+		},
+		{
+			Name:  "Old_Tablet",
+			Path:  "/home/dev/.android/avd/Old_Tablet.avd",
+			Error: "Missing system image for Google APIs x86 Old_Tablet.",
+		},
+	}
+	if !reflect.DeepEqual(avds, want) {
+		t.Errorf("ListAVDs() = %+v, want %+v", avds, want)
+	}
+}
+
+func TestEmulatorListDevices(t *testing.T) {
+	root, avdmanager, _ := newTestEmulatorSDK(t, PlatformLinux, false)
+	fake := runner.NewFake().On(avdmanager+" list device -c", runner.Result{
+		Stdout: "Warning: Observed package id 'platforms;android-34' in inconsistent location\npixel_7\npixel_7_pro\nmedium_phone\n",
+	})
+	e := &Emulator{Config: &InstallConfig{Platform: PlatformLinux, AndroidSDKPath: root}, Runner: fake}
+
+	devices, err := e.ListDevices(context.Background())
+	if err != nil {
+		t.Fatalf("ListDevices() error = %v", err)
+	}
+	if want := []string{"pixel_7", "pixel_7_pro", "medium_phone"}; !reflect.DeepEqual(devices, want) {
+		t.Errorf("ListDevices() = %q, want %q", devices, want)
+	}
+}
+
+func TestEmulatorCreateAVD(t *testing.T) {
+	root, avdmanager, _ := newTestEmulatorSDK(t, PlatformLinux, false)
+	image := "system-images;android-34;google_apis;x86_64"
+	fake := runner.NewFake().On(avdmanager+" create avd --name Pixel_7_API_34 --package "+image+" --device pixel_7", runner.Result{})
+	e := &Emulator{Config: &InstallConfig{Platform: PlatformLinux, AndroidSDKPath: root}, Runner: fake}
+
+	if err := e.CreateAVD(context.Background(), "Pixel_7_API_34", "pixel_7", image); err != nil {
+		t.Fatalf("CreateAVD() error = %v", err)
+	}
+	if err := e.CreateAVD(context.Background(), "My Phone", "pixel_7", image); err == nil {
+		t.Error("CreateAVD() accepted a name with a space")
+	}
+	if calls := fake.Calls(); len(calls) != 1 {
+		t.Errorf("ran %q, want only the valid create", calls)
+	}
+}
+
+func TestEmulatorWithoutSDK(t *testing.T) {
+	e := &Emulator{Config: &InstallConfig{Platform: PlatformLinux}, Runner: runner.NewFake()}
+	if _, err := e.ListAVDs(context.Background()); err == nil || !strings.Contains(err.Error(), "android-sdk") {
+		t.Errorf("ListAVDs() error = %v, want a hint to install the SDK", err)
+	}
+
+	e.Config.AndroidSDKPath = t.TempDir()
+	if _, err := e.ListAVDs(context.Background()); err == nil || !strings.Contains(err.Error(), "cmdline-tools;latest") {
+		t.Errorf("ListAVDs() error = %v, want a hint to install the command-line tools", err)
+	}
+}
+
+func TestEmulatorCheckAcceleration(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform Platform
+		result   runner.Result
+		want     Acceleration
+	}{
+		{
+			name:   "kvm usable",
+			result: runner.Result{Stdout: "accel:\n0\nKVM (version 12) is installed and usable.\naccel\n"},
+			want:   Acceleration{Available: true, Hypervisor: "KVM", Detail: "KVM (version 12) is installed and usable."},
+		},
+		{
+			name: "kvm without permission",
+			result: runner.Result{
+				Stdout:   "accel:\n7\nThis user doesn't have permissions to use KVM (/dev/kvm).\nThe KVM line in /etc/group is: [kvm:x:108:]\naccel\n",
+				ExitCode: 7,
+			},
+			want: Acceleration{
+				Hypervisor: "KVM",
+				Detail:     "This user doesn't have permissions to use KVM (/dev/kvm). The KVM line in /etc/group is: [kvm:x:108:]",
+			},
+		},
+		{
+			name:     "whpx",
+			platform: PlatformWindows,
+			result:   runner.Result{Stdout: "accel:\r\n0\r\nWHPX(10.0.22631) is installed and usable.\r\naccel\r\n"},
+			want:     Acceleration{Available: true, Hypervisor: "WHPX", Detail: "WHPX(10.0.22631) is installed and usable."},
+		},
+		{
+			name:     "unexpected output",
+			platform: PlatformWindows,
+			result:   runner.Result{Stderr: "segmentation fault", ExitCode: 139},
+			want:     Acceleration{Hypervisor: "WHPX", Detail: "emulator -accel-check failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platform := tt.platform
+			if platform == "" {
+				platform = PlatformLinux
+			}
+			root, _, emulator := newTestEmulatorSDK(t, platform, true)
+			fake := runner.NewFake().On(emulator+" -accel-check", tt.result)
+			e := &Emulator{Config: &InstallConfig{Platform: platform, AndroidSDKPath: root}, Runner: fake}
+
+			got := e.CheckAcceleration(context.Background())
+			if got.Available != tt.want.Available || got.Hypervisor != tt.want.Hypervisor || !strings.HasPrefix(got.Detail, tt.want.Detail) {
+				t.Errorf("CheckAcceleration() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmulatorCheckAccelerationWithoutEmulator(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands map[string]runner.Result
+		want     bool
+	}{
+		{name: "hypervisor supported", commands: map[string]runner.Result{"sysctl -n kern.hv_support": {Stdout: "1\n"}}, want: true},
+		{name: "hypervisor unsupported", commands: map[string]runner.Result{"sysctl -n kern.hv_support": {Stdout: "0\n"}}},
+		{name: "sysctl missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Emulator{
+				Config: &InstallConfig{Platform: PlatformMacOS, AndroidSDKPath: t.TempDir()},
+				Runner: &runner.Fake{Commands: tt.commands},
+			}
+			got := e.CheckAcceleration(context.Background())
+			if got.Available != tt.want || got.Hypervisor != "Hypervisor.Framework" {
+				t.Errorf("CheckAcceleration() = %+v, want available %v", got, tt.want)
+			}
+		})
+	}
+}
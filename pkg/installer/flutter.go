@@ -0,0 +1,221 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"flutter_takeoff/pkg/releases"
+	"flutter_takeoff/pkg/runner"
+)
+
+// upgradeTimeout bounds flutter upgrade and the first flutter run after
+// a checkout, which rebuild the tool and download a new engine
+const upgradeTimeout = 20 * time.Minute
+
+// FlutterChannels are the channels flutter channel can switch to. master
+// is the old name of main
+var FlutterChannels = []string{"stable", "beta", "main", "master"}
+
+// FlutterInfo is what flutter --version --machine reports about an SDK
+type FlutterInfo struct {
+	Version           string `json:"version"`
+	Channel           string `json:"channel"`
+	FrameworkRevision string `json:"framework_revision"`
+	// FrameworkDate is the date of the framework commit, as git prints it
+	FrameworkDate  string `json:"framework_date"`
+	EngineRevision string `json:"engine_revision"`
+	DartVersion    string `json:"dart_version"`
+	Root           string `json:"root"`
+}
+
+// FlutterSDK switches the channel and version of the SDK in
+// Config.FlutterPath, or of the flutter on PATH when it is empty
+type FlutterSDK struct {
+	Config *InstallConfig
+	Runner runner.CommandRunner
+	// OSName names the releases manifest the versions are listed from:
+	// "windows", "macos" or "linux"
+	OSName string
+}
+
+// flutter returns the flutter command of the SDK
+func (f *FlutterSDK) flutter() string {
+	if f.Config.FlutterPath != "" && isFlutterSDK(f.Config.FlutterPath) {
+		name := "flutter"
+		if f.Config.Platform == PlatformWindows {
+			name = "flutter.bat"
+		}
+		return filepath.Join(f.Config.FlutterPath, "bin", name)
+	}
+	return "flutter"
+}
+
+// Info runs flutter --version --machine
+func (f *FlutterSDK) Info(ctx context.Context) (*FlutterInfo, error) {
+	result, err := run(ctx, f.Runner, flutterTimeout, f.flutter(), "--version", "--machine")
+	if err != nil {
+		return nil, fmt.Errorf("flutter --version failed: %w", err)
+	}
+	return parseFlutterVersion(result.Stdout)
+}
+
+// parseFlutterVersion reads the JSON of flutter --version --machine. The
+// first run after an install or upgrade prints progress before it
+func parseFlutterVersion(output string) (*FlutterInfo, error) {
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return nil, errors.New("flutter --version printed no version information")
+	}
+
+	var machine struct {
+		FrameworkVersion    string `json:"frameworkVersion"`
+		Channel             string `json:"channel"`
+		FrameworkRevision   string `json:"frameworkRevision"`
+		FrameworkCommitDate string `json:"frameworkCommitDate"`
+		EngineRevision      string `json:"engineRevision"`
+		DartSDKVersion      string `json:"dartSdkVersion"`
+		FlutterRoot         string `json:"flutterRoot"`
+	}
+	if err := json.NewDecoder(strings.NewReader(output[start:])).Decode(&machine); err != nil {
+		return nil, fmt.Errorf("failed to parse flutter --version output: %w", err)
+	}
+	return &FlutterInfo{
+		Version:           machine.FrameworkVersion,
+		Channel:           machine.Channel,
+		FrameworkRevision: machine.FrameworkRevision,
+		FrameworkDate:     machine.FrameworkCommitDate,
+		EngineRevision:    machine.EngineRevision,
+		DartVersion:       machine.DartSDKVersion,
+		Root:              machine.FlutterRoot,
+	}, nil
+}
+
+// Releases lists the versions released on channel, newest first, from
+// the releases manifest. Each version is listed once, whatever the
+// number of archive architectures
+func (f *FlutterSDK) Releases(channel string) ([]releases.Release, error) {
+	manifest, err := releases.Fetch(http.DefaultClient, releaseURL(f.Config, releases.ManifestName(f.OSName)))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []releases.Release
+	seen := make(map[string]bool)
+	for _, r := range manifest.Filter(channel, "") {
+		if !seen[r.Version] {
+			seen[r.Version] = true
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// ResolveVersion turns "latest" or a release line such as "3.22.x" into
+// the version it stands for on channel. Exact versions are returned
+// without fetching the manifest, but spelt as in the manifest, so
+// "v3.24.5" is "3.24.5"
+func (f *FlutterSDK) ResolveVersion(query, channel string) (string, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "v")
+	if query != "" && query != "latest" && !strings.HasSuffix(query, ".x") {
+		return query, nil
+	}
+
+	manifest, err := releases.Fetch(http.DefaultClient, releaseURL(f.Config, releases.ManifestName(f.OSName)))
+	if err != nil {
+		return "", err
+	}
+	release, err := manifest.Resolve(query, channel, "")
+	if err != nil {
+		return "", err
+	}
+	return release.Version, nil
+}
+
+// SwitchChannel runs flutter channel and then flutter upgrade, which
+// moves the SDK to the newest release of the channel
+func (f *FlutterSDK) SwitchChannel(ctx context.Context, channel string) error {
+	if !slices.Contains(FlutterChannels, channel) {
+		return fmt.Errorf("unknown channel %q: use one of %s", channel, strings.Join(FlutterChannels, ", "))
+	}
+
+	flutter := f.flutter()
+	if _, err := run(ctx, f.Runner, flutterTimeout, flutter, "channel", channel); err != nil {
+		return fmt.Errorf("flutter channel %s failed: %w", channel, err)
+	}
+	if result, err := run(ctx, f.Runner, upgradeTimeout, flutter, "upgrade"); err != nil {
+		return fmt.Errorf("flutter upgrade failed: %w%s", err, lastLine(result.Combined()))
+	}
+	f.Config.Channel = channel
+	return nil
+}
+
+// Checkout checks out the git tag of version in the SDK, which must be
+// a git clone without local changes, then runs flutter once so it
+// rebuilds the tool and downloads the matching engine
+func (f *FlutterSDK) Checkout(ctx context.Context, version string) error {
+	root, err := f.root(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git")); err != nil {
+		return fmt.Errorf("%s is not a git clone, so its version cannot be switched; reinstall with 'flutter-takeoff install --version %s'", root, version)
+	}
+
+	result, err := run(ctx, f.Runner, probeTimeout, "git", "-C", root, "status", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("git status failed in %s: %w", root, err)
+	}
+	if strings.TrimSpace(result.Stdout) != "" {
+		return fmt.Errorf("%s has local changes; commit or discard them before switching versions", root)
+	}
+
+	if result, err := run(ctx, f.Runner, flutterTimeout, "git", "-C", root, "fetch", "--tags", "origin"); err != nil {
+		return fmt.Errorf("git fetch failed in %s: %w%s", root, err, lastLine(result.Combined()))
+	}
+	if _, err := run(ctx, f.Runner, probeTimeout, "git", "-C", root, "rev-parse", "--verify", "--quiet", "refs/tags/"+version); err != nil {
+		return fmt.Errorf("Flutter %s has no tag in %s", version, root)
+	}
+	if result, err := run(ctx, f.Runner, probeTimeout, "git", "-C", root, "checkout", "--quiet", version); err != nil {
+		return fmt.Errorf("git checkout %s failed: %w%s", version, err, lastLine(result.Combined()))
+	}
+
+	if result, err := run(ctx, f.Runner, upgradeTimeout, f.flutter(), "--version"); err != nil {
+		return fmt.Errorf("flutter failed to set up %s: %w%s", version, err, lastLine(result.Combined()))
+	}
+	f.Config.FlutterVersion = version
+	return nil
+}
+
+// root returns the SDK directory: Config.FlutterPath, or where the
+// flutter on PATH says it lives
+func (f *FlutterSDK) root(ctx context.Context) (string, error) {
+	if f.Config.FlutterPath != "" {
+		return f.Config.FlutterPath, nil
+	}
+	info, err := f.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	if info.Root == "" {
+		return "", errors.New("flutter --version did not report the SDK directory")
+	}
+	return info.Root, nil
+}
+
+// lastLine returns the last non-empty line of a command's output as
+// ": line", to explain why it failed, or "" when there is none
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return ": " + last
+	}
+	return ""
+}
@@ -0,0 +1,220 @@
+package installer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+const testFlutterVersion = `Downloading Dart SDK from Flutter engine a18df97ca57a249df5d8d68cd0820600223ce262...
+{
+  "frameworkVersion": "3.24.5",
+  "channel": "stable",
+  "repositoryUrl": "https://github.com/flutter/flutter.git",
+  "frameworkRevision": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668",
+  "frameworkCommitDate": "2024-11-05 10:37:23 -0800",
+  "engineRevision": "a18df97ca57a249df5d8d68cd0820600223ce262",
+  "dartSdkVersion": "3.5.4",
+  "devToolsVersion": "2.37.3",
+  "flutterVersion": "3.24.5",
+  "flutterRoot": "/home/dev/flutter"
+}
+`
+
+const testMultiArchManifest = `{
+  "current_release": {"stable": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668", "beta": "2e2c358c9b14765c90f4ea6ab0d3b1b6c3d4e5f6"},
+  "releases": [
+    {"hash": "2e2c358c9b14765c90f4ea6ab0d3b1b6c3d4e5f6", "channel": "beta", "version": "3.27.0-0.1.pre", "dart_sdk_arch": "arm64", "archive": "beta/macos/flutter_macos_arm64_3.27.0-0.1.pre-beta.zip"},
+    {"hash": "2e2c358c9b14765c90f4ea6ab0d3b1b6c3d4e5f6", "channel": "beta", "version": "3.27.0-0.1.pre", "dart_sdk_arch": "x64", "archive": "beta/macos/flutter_macos_3.27.0-0.1.pre-beta.zip"},
+    {"hash": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668", "channel": "stable", "version": "3.24.5", "dart_sdk_arch": "arm64", "archive": "stable/macos/flutter_macos_arm64_3.24.5-stable.zip"},
+    {"hash": "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668", "channel": "stable", "version": "3.24.5", "dart_sdk_arch": "x64", "archive": "stable/macos/flutter_macos_3.24.5-stable.zip"},
+    {"hash": "603104015dd692ea3403755b55d07813d5cf8965", "channel": "stable", "version": "3.24.4", "dart_sdk_arch": "x64", "archive": "stable/macos/flutter_macos_3.24.4-stable.zip"}
+  ]
+}`
+
+// newTestFlutterSDK creates an SDK directory that is a git clone
+func newTestFlutterSDK(t *testing.T) (root, flutter string) {
+	t.Helper()
+
+	root = t.TempDir()
+	flutter = filepath.Join(root, "bin", "flutter")
+	for _, dir := range []string{filepath.Dir(flutter), filepath.Join(root, ".git")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(flutter, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return root, flutter
+}
+
+func TestFlutterInfo(t *testing.T) {
+	root, flutter := newTestFlutterSDK(t)
+	fake := runner.NewFake().On(flutter+" --version --machine", runner.Result{Stdout: testFlutterVersion})
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformLinux, FlutterPath: root}, Runner: fake}
+
+	info, err := f.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	want := &FlutterInfo{
+		Version:           "3.24.5",
+		Channel:           "stable",
+		FrameworkRevision: "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668",
+		FrameworkDate:     "2024-11-05 10:37:23 -0800",
+		EngineRevision:    "a18df97ca57a249df5d8d68cd0820600223ce262",
+		DartVersion:       "3.5.4",
+		Root:              "/home/dev/flutter",
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("Info() = %+v, want %+v", info, want)
+	}
+
+	if _, err := parseFlutterVersion("Flutter 3.24.5 • channel stable"); err == nil {
+		t.Error("parseFlutterVersion() accepted output without JSON")
+	}
+}
+
+func TestFlutterOnPath(t *testing.T) {
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformLinux}, Runner: runner.NewFake()}
+	if got := f.flutter(); got != "flutter" {
+		t.Errorf("flutter() without FlutterPath = %q, want flutter", got)
+	}
+	f.Config.FlutterPath = t.TempDir()
+	if got := f.flutter(); got != "flutter" {
+		t.Errorf("flutter() in a folder without an SDK = %q, want flutter", got)
+	}
+}
+
+func TestFlutterSwitchChannel(t *testing.T) {
+	root, flutter := newTestFlutterSDK(t)
+	fake := runner.NewFake().
+		On(flutter+" channel beta", runner.Result{}).
+		On(flutter+" upgrade", runner.Result{})
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformLinux, FlutterPath: root}, Runner: fake}
+
+	if err := f.SwitchChannel(context.Background(), "beta"); err != nil {
+		t.Fatalf("SwitchChannel() error = %v", err)
+	}
+	want := []string{flutter + " channel beta", flutter + " upgrade"}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+	if f.Config.Channel != "beta" {
+		t.Errorf("Config.Channel = %q, want beta", f.Config.Channel)
+	}
+
+	if err := f.SwitchChannel(context.Background(), "dev"); err == nil {
+		t.Error("SwitchChannel() accepted the retired dev channel")
+	}
+}
+
+func TestFlutterSwitchChannelUpgradeFails(t *testing.T) {
+	root, flutter := newTestFlutterSDK(t)
+	fake := runner.NewFake().
+		On(flutter+" channel beta", runner.Result{}).
+		On(flutter+" upgrade", runner.Result{Stderr: "Network error\nUnable to upgrade Flutter\n", ExitCode: 1})
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformLinux, FlutterPath: root}, Runner: fake}
+
+	err := f.SwitchChannel(context.Background(), "beta")
+	if err == nil || !strings.HasSuffix(err.Error(), ": Unable to upgrade Flutter") {
+		t.Errorf("SwitchChannel() error = %v, want it to end with flutter's last line", err)
+	}
+	if f.Config.Channel != "" {
+		t.Errorf("Config.Channel = %q after a failed upgrade", f.Config.Channel)
+	}
+}
+
+func TestFlutterCheckout(t *testing.T) {
+	root, flutter := newTestFlutterSDK(t)
+	git := "git -C " + root
+	fake := runner.NewFake().
+		On(git+" status --porcelain", runner.Result{}).
+		On(git+" fetch --tags origin", runner.Result{}).
+		On(git+" rev-parse --verify --quiet refs/tags/3.22.3", runner.Result{Stdout: "b0850beeb25f6d5b10426284f506557f66181b36\n"}).
+		On(git+" checkout --quiet 3.22.3", runner.Result{}).
+		On(flutter+" --version", runner.Result{})
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformLinux, FlutterPath: root}, Runner: fake}
+
+	if err := f.Checkout(context.Background(), "3.22.3"); err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if got := fake.Calls(); len(got) != 5 || got[3] != git+" checkout --quiet 3.22.3" {
+		t.Errorf("calls = %q", got)
+	}
+	if f.Config.FlutterVersion != "3.22.3" {
+		t.Errorf("Config.FlutterVersion = %q, want 3.22.3", f.Config.FlutterVersion)
+	}
+
+	// An unknown version stops before anything is checked out
+	if err := f.Checkout(context.Background(), "9.9.9"); err == nil || !strings.Contains(err.Error(), "no tag") {
+		t.Errorf("Checkout(9.9.9) error = %v, want no tag", err)
+	}
+}
+
+func TestFlutterCheckoutRefusesLocalChanges(t *testing.T) {
+	root, _ := newTestFlutterSDK(t)
+	fake := runner.NewFake().On("git -C "+root+" status --porcelain", runner.Result{Stdout: " M packages/flutter/lib/src/widgets/text.dart\n"})
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformLinux, FlutterPath: root}, Runner: fake}
+
+	if err := f.Checkout(context.Background(), "3.22.3"); err == nil || !strings.Contains(err.Error(), "local changes") {
+		t.Errorf("Checkout() error = %v, want local changes", err)
+	}
+	if calls := fake.Calls(); len(calls) != 1 {
+		t.Errorf("calls = %q, want only git status", calls)
+	}
+}
+
+func TestFlutterCheckoutNeedsGitClone(t *testing.T) {
+	root, _ := newTestFlutterSDK(t)
+	if err := os.Remove(filepath.Join(root, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformLinux, FlutterPath: root}, Runner: runner.NewFake()}
+
+	if err := f.Checkout(context.Background(), "3.22.3"); err == nil || !strings.Contains(err.Error(), "not a git clone") {
+		t.Errorf("Checkout() error = %v, want not a git clone", err)
+	}
+}
+
+func TestFlutterReleases(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/flutter_infra_release/releases/releases_macos.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testMultiArchManifest))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	f := &FlutterSDK{Config: &InstallConfig{Platform: PlatformMacOS, StorageBaseURL: server.URL}, Runner: runner.NewFake(), OSName: "macos"}
+
+	stable, err := f.Releases("stable")
+	if err != nil {
+		t.Fatalf("Releases() error = %v", err)
+	}
+	var versions []string
+	for _, r := range stable {
+		versions = append(versions, r.Version)
+	}
+	if want := []string{"3.24.5", "3.24.4"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("stable versions = %q, want %q", versions, want)
+	}
+
+	tests := []struct{ query, channel, want string }{
+		{"latest", "beta", "3.27.0-0.1.pre"},
+		{"3.24.x", "stable", "3.24.5"},
+		{"3.19.6", "stable", "3.19.6"},
+		{" v3.24.5", "stable", "3.24.5"},
+	}
+	for _, tt := range tests {
+		if got, err := f.ResolveVersion(tt.query, tt.channel); err != nil || got != tt.want {
+			t.Errorf("ResolveVersion(%q, %q) = %q, %v; want %q", tt.query, tt.channel, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/jdk"
+)
+
+// Installer is implemented by every platform-specific installer
+type Installer interface {
+	// CheckDependencies checks if required dependencies are installed.
+	// Each probe has its own timeout; all of them stop when ctx is done
+	CheckDependencies(ctx context.Context) []Dependency
+	// Probes returns the individual dependency checks, for callers that
+	// show each result as soon as it is known
+	Probes() []Probe
+	// FindJDKs lists the Java installations found on the machine
+	FindJDKs(ctx context.Context) []jdk.Install
+	// SetJavaHome makes javaHome the user's JAVA_HOME for new terminals
+	// and for this process, and describes what changed
+	SetJavaHome(javaHome string) (string, error)
+	// SetFlutterJDK runs flutter config --jdk-dir so flutter builds with
+	// javaHome
+	SetFlutterJDK(ctx context.Context, javaHome string) error
+	// GetDefaultFlutterPath returns the default Flutter installation path
+	GetDefaultFlutterPath() string
+	// GetDefaultAndroidSDKPath returns where the Android SDK is installed
+	// when InstallConfig.AndroidSDKPath is empty
+	GetDefaultAndroidSDKPath() string
+	// AndroidSDKInventory lists the components installed in the Android
+	// SDK, using the usual SDK locations when InstallConfig.AndroidSDKPath
+	// is empty
+	AndroidSDKInventory() (*androidsdk.Inventory, error)
+	// AndroidRequirements returns the SDK components the configured
+	// Flutter version needs, with system images for the machine's CPU
+	AndroidRequirements(ctx context.Context) androidsdk.Requirements
+	// Emulator manages AVDs with the tools of the SDK in
+	// InstallConfig.AndroidSDKPath, which AndroidSDKInventory fills in
+	Emulator() *Emulator
+	// Flutter switches the channel and version of the SDK in
+	// InstallConfig.FlutterPath, or of the flutter on PATH
+	Flutter() *FlutterSDK
+	// InstallAndroidSDK installs the Android command-line tools into
+	// InstallConfig.AndroidSDKPath and the configured SDK packages
+	InstallAndroidSDK(ctx context.Context, progressCallback func(percent int, status string)) error
+	// DownloadFlutter downloads and extracts Flutter SDK
+	DownloadFlutter(ctx context.Context, progressCallback func(percent int, status string)) error
+	// SetupEnvironmentPath adds Flutter to PATH and describes what changed
+	SetupEnvironmentPath() (string, error)
+	// AcceptAndroidLicenses runs flutter doctor --android-licenses
+	AcceptAndroidLicenses(ctx context.Context) error
+	// AndroidLicenses fetches the texts of the licences in the Android
+	// repository, for review
+	AndroidLicenses() ([]androidsdk.License, error)
+	// WriteAndroidLicenses accepts the given licences, as fetched by
+	// AndroidLicenses, without prompting, in the SDK at
+	// InstallConfig.AndroidSDKPath
+	WriteAndroidLicenses(licenses []androidsdk.License) error
+	// RunFlutterDoctor runs flutter doctor to verify installation
+	RunFlutterDoctor(ctx context.Context) (string, error)
+}
+
+// DetectPlatform returns the Platform matching the running operating system
+func DetectPlatform() Platform {
+	switch runtime.GOOS {
+	case "windows":
+		return PlatformWindows
+	case "darwin":
+		return PlatformMacOS
+	case "linux":
+		return PlatformLinux
+	default:
+		return Platform(runtime.GOOS)
+	}
+}
+
+// New creates the installer for config.Platform, detecting the platform
+// from the running operating system when it is not set
+func New(config *InstallConfig) (Installer, error) {
+	if config.Platform == "" {
+		config.Platform = DetectPlatform()
+	}
+
+	switch config.Platform {
+	case PlatformWindows:
+		return NewWindowsInstaller(config), nil
+	case PlatformMacOS:
+		return NewMacOSInstaller(config), nil
+	case PlatformLinux:
+		return NewLinuxInstaller(config), nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", config.Platform)
+	}
+}
@@ -0,0 +1,161 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"flutter_takeoff/pkg/jdk"
+	"flutter_takeoff/pkg/runner"
+)
+
+const javaHint = "Install a JDK 17 or newer, e.g. Eclipse Temurin from https://adoptium.net/"
+
+// checkJava finds the JDK Flutter builds with: JAVA_HOME when it is set,
+// as Gradle prefers it, and the java on PATH otherwise. It has to be
+// Java 17 or newer and a full JDK with javac. When it is not, the hint
+// names a JDK found elsewhere on the machine that would work
+func checkJava(ctx context.Context, r runner.CommandRunner, config *InstallConfig) Dependency {
+	dep := inspectJava(ctx, r, config)
+	if dep.IsInstalled {
+		return dep
+	}
+
+	if best, ok := jdk.Recommend(findJDKs(ctx, r, config)); ok {
+		dep.Hint = fmt.Sprintf("Java %s is installed at %s; run 'flutter-takeoff jdk --use recommended' to use it",
+			best.Info, best.Home)
+	}
+	return dep
+}
+
+// inspectJava checks the JDK in JAVA_HOME or on PATH and records its home
+// in config.JavaPath
+func inspectJava(ctx context.Context, r runner.CommandRunner, config *InstallConfig) Dependency {
+	dep := Dependency{
+		Name:        "Java JDK",
+		Description: "Java Development Kit 17+ (required for Android development)",
+		Required:    true,
+		Hint:        javaHint,
+	}
+
+	java := "java"
+	if path, err := r.LookPath("java"); err == nil {
+		java = path
+		dep.Path = path
+	}
+
+	// java -version prints to stderr
+	var pathInfo *jdk.Info
+	if result, err := run(ctx, r, probeTimeout, "java", "-version"); err == nil {
+		info, err := jdk.Parse(result.Combined())
+		if err != nil {
+			dep.Problem = "Could not read the Java version: " + err.Error()
+			return dep
+		}
+		pathInfo = &info
+	}
+
+	info, mismatch := pathInfo, ""
+	if pathInfo != nil {
+		config.JavaPath = jdk.HomeOf(java)
+	}
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		config.JavaPath = home
+
+		homeJava := filepath.Join(home, "bin", executable(config.Platform, "java"))
+		if _, err := os.Stat(homeJava); err != nil {
+			dep.Problem = fmt.Sprintf("JAVA_HOME is set to %s, which has no bin/%s", home, filepath.Base(homeJava))
+			dep.Hint = "Point JAVA_HOME at a JDK 17 or newer, or unset it to use the java on PATH"
+			return dep
+		}
+
+		result, err := run(ctx, r, probeTimeout, homeJava, "-version")
+		if err != nil {
+			dep.Problem = fmt.Sprintf("The java in JAVA_HOME (%s) does not run: %v", home, err)
+			dep.Hint = "Point JAVA_HOME at a JDK 17 or newer, or unset it to use the java on PATH"
+			return dep
+		}
+		homeInfo, err := jdk.Parse(result.Combined())
+		if err != nil {
+			dep.Problem = "Could not read the Java version in JAVA_HOME: " + err.Error()
+			return dep
+		}
+
+		if pathInfo != nil && *pathInfo != homeInfo {
+			mismatch = fmt.Sprintf("java on PATH is %s but JAVA_HOME is %s; Flutter builds use JAVA_HOME", pathInfo, homeInfo)
+		}
+		java, info = homeJava, &homeInfo
+		dep.Path = homeJava
+	}
+
+	if info == nil {
+		return dep
+	}
+	dep.Version = info.String()
+
+	if !info.Supported() {
+		dep.Problem = fmt.Sprintf("Java %d is too old; Flutter needs JDK %d or newer", info.Version.Major, jdk.MinimumMajor)
+		if config.JavaPath != "" {
+			dep.Hint = "Install a JDK 17 or newer and point JAVA_HOME at it, e.g. Eclipse Temurin from https://adoptium.net/"
+		}
+		return dep
+	}
+
+	// A JRE has java but no javac next to it
+	javac := "javac"
+	if java != "java" {
+		javac = filepath.Join(filepath.Dir(resolveSymlinks(java)), executable(config.Platform, "javac"))
+	}
+	result, err := run(ctx, r, probeTimeout, javac, "-version")
+	if err != nil {
+		dep.Problem = fmt.Sprintf("Java %d is a runtime (JRE) without javac; Flutter needs a full JDK", info.Version.Major)
+		return dep
+	}
+	// Gradle compiles with this javac, so it should be the same release
+	if v, err := jdk.ParseJavac(result.Combined()); err == nil && v.Major != info.Version.Major && mismatch == "" {
+		mismatch = fmt.Sprintf("javac is %s but java is %s; Gradle may compile with a different JDK than it runs on", v, info.Version)
+	}
+
+	dep.IsInstalled = true
+	dep.Problem = mismatch
+	return dep
+}
+
+// resolveSymlinks follows links such as /usr/bin/java ->
+// /etc/alternatives/java -> /usr/lib/jvm/..., returning path unchanged
+// when it cannot be resolved
+func resolveSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// findJDKs lists the JDKs installed on the machine, starting with the ones
+// in JAVA_HOME and on PATH
+func findJDKs(ctx context.Context, r runner.CommandRunner, config *InstallConfig) []jdk.Install {
+	home, _ := os.UserHomeDir()
+	locations := jdk.Locations(goos(config.Platform), home, os.Getenv)
+
+	// macOS ships a /usr/bin/java stub that is not part of a JDK
+	if java, err := r.LookPath("java"); err == nil && java != "/usr/bin/java" {
+		at := 0
+		if len(locations) > 0 && locations[0].Source == "JAVA_HOME" {
+			at = 1
+		}
+		locations = append(locations[:at], append([]jdk.Location{{Pattern: jdk.HomeOf(java), Source: "PATH"}}, locations[at:]...)...)
+	}
+
+	return jdk.Discover(ctx, r, goos(config.Platform), locations)
+}
+
+// setFlutterJDK makes flutter build with the JDK at javaHome, whatever
+// JAVA_HOME says
+func setFlutterJDK(ctx context.Context, r runner.CommandRunner, config *InstallConfig, javaHome string) error {
+	if _, err := run(ctx, r, flutterTimeout, "flutter", "config", "--jdk-dir="+javaHome); err != nil {
+		return fmt.Errorf("flutter config --jdk-dir failed: %w", err)
+	}
+	config.JavaPath = javaHome
+	return nil
+}
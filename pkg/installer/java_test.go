@@ -0,0 +1,217 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+const (
+	temurin17 = "openjdk version \"17.0.11\" 2024-04-16\n" +
+		"OpenJDK Runtime Environment Temurin-17.0.11+9 (build 17.0.11+9)\n" +
+		"OpenJDK 64-Bit Server VM Temurin-17.0.11+9 (build 17.0.11+9, mixed mode, sharing)\n"
+	openjdk11 = "openjdk version \"11.0.22\" 2024-01-16\n" +
+		"OpenJDK Runtime Environment (build 11.0.22+7-post-Ubuntu-0ubuntu222.04.1)\n" +
+		"OpenJDK 64-Bit Server VM (build 11.0.22+7-post-Ubuntu-0ubuntu222.04.1, mixed mode, sharing)\n"
+	jbr21 = "openjdk version \"21.0.3\" 2024-04-16\n" +
+		"OpenJDK Runtime Environment (build 21.0.3+-12282718-b509.11)\n" +
+		"OpenJDK 64-Bit Server VM (build 21.0.3+-12282718-b509.11, mixed mode)\n"
+)
+
+// newJavaHome creates a JDK directory with an empty bin/java
+func newJavaHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "bin", "java"), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+func TestCheckJava(t *testing.T) {
+	const pathJava = "/usr/lib/jvm/temurin-17/bin/java"
+	home := newJavaHome(t)
+	homeJava := filepath.Join(home, "bin", "java")
+	emptyHome := t.TempDir()
+
+	tests := []struct {
+		name          string
+		fake          *runner.Fake
+		javaHome      string
+		wantInstalled bool
+		wantVersion   string
+		wantPath      string
+		wantProblem   string
+		wantJavaPath  string
+	}{
+		{
+			name: "temurin 17 on PATH",
+			fake: runner.NewFake().
+				OnPath("java", pathJava).
+				On("java -version", runner.Result{Stderr: temurin17}).
+				On("/usr/lib/jvm/temurin-17/bin/javac -version", runner.Result{Stdout: "javac 17.0.11\n"}),
+			wantInstalled: true,
+			wantVersion:   "17.0.11 (Eclipse Temurin)",
+			wantPath:      pathJava,
+			wantJavaPath:  "/usr/lib/jvm/temurin-17",
+		},
+		{
+			name: "javac from another release",
+			fake: runner.NewFake().
+				OnPath("java", pathJava).
+				On("java -version", runner.Result{Stderr: temurin17}).
+				On("/usr/lib/jvm/temurin-17/bin/javac -version", runner.Result{Stdout: "javac 11.0.22\n"}),
+			wantInstalled: true,
+			wantVersion:   "17.0.11 (Eclipse Temurin)",
+			wantPath:      pathJava,
+			wantProblem:   "javac is 11.0.22 but java is 17.0.11",
+			wantJavaPath:  "/usr/lib/jvm/temurin-17",
+		},
+		{
+			name: "too old",
+			fake: runner.NewFake().
+				OnPath("java", "/usr/lib/jvm/java-11-openjdk-amd64/bin/java").
+				On("java -version", runner.Result{Stderr: openjdk11}),
+			wantVersion:  "11.0.22 (OpenJDK)",
+			wantPath:     "/usr/lib/jvm/java-11-openjdk-amd64/bin/java",
+			wantProblem:  "Java 11 is too old",
+			wantJavaPath: "/usr/lib/jvm/java-11-openjdk-amd64",
+		},
+		{
+			name: "runtime without javac",
+			fake: runner.NewFake().
+				OnPath("java", pathJava).
+				On("java -version", runner.Result{Stderr: temurin17}),
+			wantVersion:  "17.0.11 (Eclipse Temurin)",
+			wantPath:     pathJava,
+			wantProblem:  "without javac",
+			wantJavaPath: "/usr/lib/jvm/temurin-17",
+		},
+		{
+			name: "unreadable version",
+			fake: runner.NewFake().
+				OnPath("java", pathJava).
+				On("java -version", runner.Result{Stderr: "Error: could not open `jvm.cfg'\n"}),
+			wantPath:    pathJava,
+			wantProblem: "Could not read the Java version",
+		},
+		{
+			name: "macOS stub without a JDK",
+			fake: runner.NewFake().
+				OnPath("java", "/usr/bin/java").
+				On("java -version", runner.Result{Stderr: "The operation couldn’t be completed. Unable to locate a Java Runtime.\n", ExitCode: 1}),
+			wantPath: "/usr/bin/java",
+		},
+		{
+			name: "JAVA_HOME wins over PATH",
+			fake: runner.NewFake().
+				OnPath("java", "/usr/lib/jvm/java-11-openjdk-amd64/bin/java").
+				On("java -version", runner.Result{Stderr: openjdk11}).
+				On(homeJava+" -version", runner.Result{Stderr: jbr21}).
+				On(filepath.Join(home, "bin", "javac")+" -version", runner.Result{Stdout: "javac 21.0.3\n"}),
+			javaHome:      home,
+			wantInstalled: true,
+			wantVersion:   "21.0.3 (JetBrains Runtime)",
+			wantPath:      homeJava,
+			wantProblem:   "java on PATH is 11.0.22 (OpenJDK) but JAVA_HOME is 21.0.3 (JetBrains Runtime)",
+			wantJavaPath:  home,
+		},
+		{
+			name: "JAVA_HOME without java",
+			fake: runner.NewFake().
+				OnPath("java", pathJava).
+				On("java -version", runner.Result{Stderr: temurin17}),
+			javaHome:     emptyHome,
+			wantPath:     pathJava,
+			wantProblem:  "which has no bin/java",
+			wantJavaPath: emptyHome,
+		},
+		{
+			name: "JAVA_HOME too old",
+			fake: runner.NewFake().
+				OnPath("java", pathJava).
+				On("java -version", runner.Result{Stderr: temurin17}).
+				On(homeJava+" -version", runner.Result{Stderr: openjdk11}),
+			javaHome:     home,
+			wantVersion:  "11.0.22 (OpenJDK)",
+			wantPath:     homeJava,
+			wantProblem:  "Java 11 is too old",
+			wantJavaPath: home,
+		},
+		{
+			name: "missing",
+			fake: runner.NewFake(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JAVA_HOME", tt.javaHome)
+			config := &InstallConfig{Platform: PlatformLinux}
+			dep := checkJava(context.Background(), tt.fake, config)
+
+			if dep.IsInstalled != tt.wantInstalled || dep.Version != tt.wantVersion || dep.Path != tt.wantPath {
+				t.Errorf("checkJava = installed %v, version %q, path %q; want %v, %q, %q",
+					dep.IsInstalled, dep.Version, dep.Path, tt.wantInstalled, tt.wantVersion, tt.wantPath)
+			}
+			if (tt.wantProblem == "") != (dep.Problem == "") || !strings.Contains(dep.Problem, tt.wantProblem) {
+				t.Errorf("problem = %q, want %q", dep.Problem, tt.wantProblem)
+			}
+			if config.JavaPath != tt.wantJavaPath {
+				t.Errorf("config.JavaPath = %q, want %q", config.JavaPath, tt.wantJavaPath)
+			}
+		})
+	}
+}
+
+func TestCheckJavaRecommendsInstalledJDK(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("JAVA_HOME", "")
+
+	// A JDK 17 that IntelliJ downloaded, while PATH has Java 11
+	temurin := filepath.Join(home, ".jdks", "temurin-17.0.11")
+	for _, name := range []string{"java", "javac"} {
+		if err := os.MkdirAll(filepath.Join(temurin, "bin"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(temurin, "bin", name), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fake := runner.NewFake().
+		OnPath("java", "/usr/lib/jvm/java-11-openjdk-amd64/bin/java").
+		On("java -version", runner.Result{Stderr: openjdk11}).
+		On(filepath.Join(temurin, "bin", "java")+" -version", runner.Result{Stderr: temurin17})
+
+	dep := checkJava(context.Background(), fake, &InstallConfig{Platform: PlatformLinux})
+	if dep.IsInstalled {
+		t.Fatal("Java 11 should not satisfy the check")
+	}
+	if !strings.Contains(dep.Hint, "17.0.11 (Eclipse Temurin) is installed at "+temurin) {
+		t.Errorf("hint = %q, want it to recommend %s", dep.Hint, temurin)
+	}
+}
+
+func TestSetFlutterJDK(t *testing.T) {
+	fake := runner.NewFake().On("flutter config --jdk-dir=/opt/jdk-17", runner.Result{})
+	config := &InstallConfig{Platform: PlatformLinux}
+
+	if err := setFlutterJDK(context.Background(), fake, config, "/opt/jdk-17"); err != nil {
+		t.Fatal(err)
+	}
+	if config.JavaPath != "/opt/jdk-17" {
+		t.Errorf("config.JavaPath = %q", config.JavaPath)
+	}
+
+	if err := setFlutterJDK(context.Background(), runner.NewFake(), config, "/opt/other"); err == nil {
+		t.Error("expected an error when flutter is missing")
+	}
+}
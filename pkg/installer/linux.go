@@ -0,0 +1,189 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/jdk"
+	"flutter_takeoff/pkg/runner"
+)
+
+// LinuxInstaller handles Flutter installation on Linux
+type LinuxInstaller struct {
+	Config *InstallConfig
+	Runner runner.CommandRunner
+}
+
+var _ Installer = (*LinuxInstaller)(nil)
+
+// NewLinuxInstaller creates a new Linux installer
+func NewLinuxInstaller(config *InstallConfig) *LinuxInstaller {
+	return &LinuxInstaller{Config: config, Runner: runner.Exec{}}
+}
+
+// CheckDependencies checks if required dependencies are installed
+func (l *LinuxInstaller) CheckDependencies(ctx context.Context) []Dependency {
+	return RunProbes(ctx, l.Probes(), nil)
+}
+
+// Probes returns the dependency checks, in the order they are reported
+func (l *LinuxInstaller) Probes() []Probe {
+	return []Probe{
+		{Name: "Git", Check: func(ctx context.Context) Dependency { return checkGit(ctx, l.Runner, l.Config) }},
+		{Name: "Java JDK", Check: func(ctx context.Context) Dependency { return checkJava(ctx, l.Runner, l.Config) }},
+		{Name: "Android SDK", Check: l.checkAndroidSDK},
+		{Name: "Linux Toolchain", Check: l.checkDesktopToolchain},
+		{Name: "Flutter SDK", Timeout: flutterTimeout, Check: func(ctx context.Context) Dependency { return checkFlutter(ctx, l.Runner, l.Config) }},
+	}
+}
+
+func (l *LinuxInstaller) checkAndroidSDK(ctx context.Context) Dependency {
+	return checkAndroidSDKPaths(ctx, l.Runner, l.Config, l.AndroidRequirements(ctx), l.androidSDKPaths())
+}
+
+// androidSDKPaths returns the candidate Android SDK roots, in order
+func (l *LinuxInstaller) androidSDKPaths() []string {
+	home, _ := os.UserHomeDir()
+
+	possiblePaths := []string{
+		os.Getenv("ANDROID_HOME"),
+		os.Getenv("ANDROID_SDK_ROOT"),
+	}
+	if home != "" {
+		possiblePaths = append(possiblePaths, filepath.Join(home, "Android", "Sdk"))
+	}
+
+	return possiblePaths
+}
+
+// AndroidSDKInventory lists the components installed in the Android SDK
+func (l *LinuxInstaller) AndroidSDKInventory() (*androidsdk.Inventory, error) {
+	return androidSDKInventory(l.Config, l.androidSDKPaths())
+}
+
+// AndroidRequirements returns the SDK components the configured Flutter
+// version needs
+func (l *LinuxInstaller) AndroidRequirements(ctx context.Context) androidsdk.Requirements {
+	return androidRequirements(l.Config, runtime.GOARCH == "arm64")
+}
+
+// Emulator manages AVDs with the SDK's avdmanager and emulator
+func (l *LinuxInstaller) Emulator() *Emulator {
+	return &Emulator{Config: l.Config, Runner: l.Runner}
+}
+
+// Flutter switches the channel and version of the Flutter SDK
+func (l *LinuxInstaller) Flutter() *FlutterSDK {
+	return &FlutterSDK{Config: l.Config, Runner: l.Runner, OSName: "linux"}
+}
+
+// checkDesktopToolchain checks the tools flutter needs to build Linux desktop apps
+func (l *LinuxInstaller) checkDesktopToolchain(ctx context.Context) Dependency {
+	dep := Dependency{
+		Name:        "Linux Toolchain",
+		Description: "clang, CMake, Ninja, pkg-config and GTK 3 headers (required for Linux desktop)",
+		Required:    l.Config.Target == TargetDesktop,
+		Hint:        "sudo apt-get install clang cmake ninja-build pkg-config libgtk-3-dev",
+	}
+
+	// Debian package providing each tool, for the hint
+	tools := []struct{ command, pkg string }{
+		{"clang++", "clang"},
+		{"cmake", "cmake"},
+		{"ninja", "ninja-build"},
+		{"pkg-config", "pkg-config"},
+	}
+
+	var found, missing []string
+	for _, tool := range tools {
+		if path, err := l.Runner.LookPath(tool.command); err == nil {
+			found = append(found, tool.command)
+			if dep.Path == "" {
+				dep.Path = path
+			}
+		} else {
+			missing = append(missing, tool.pkg)
+		}
+	}
+
+	// GTK 3 headers are only visible through pkg-config
+	if result, err := run(ctx, l.Runner, probeTimeout, "pkg-config", "--modversion", "gtk+-3.0"); err == nil {
+		found = append(found, "gtk+-3.0 "+strings.TrimSpace(result.Stdout))
+	} else {
+		missing = append(missing, "libgtk-3-dev")
+	}
+
+	if len(missing) == 0 {
+		dep.IsInstalled = true
+		dep.Version = strings.Join(found, ", ")
+	} else {
+		dep.Hint = "sudo apt-get install " + strings.Join(missing, " ")
+	}
+
+	return dep
+}
+
+// FindJDKs lists the Java installations found on the machine
+func (l *LinuxInstaller) FindJDKs(ctx context.Context) []jdk.Install {
+	return findJDKs(ctx, l.Runner, l.Config)
+}
+
+// SetJavaHome sets JAVA_HOME in the shell startup file
+func (l *LinuxInstaller) SetJavaHome(javaHome string) (string, error) {
+	return setUnixJavaHome(l.Config, javaHome)
+}
+
+// SetFlutterJDK runs flutter config --jdk-dir
+func (l *LinuxInstaller) SetFlutterJDK(ctx context.Context, javaHome string) error {
+	return setFlutterJDK(ctx, l.Runner, l.Config, javaHome)
+}
+
+// GetDefaultFlutterPath returns the default Flutter installation path
+func (l *LinuxInstaller) GetDefaultFlutterPath() string {
+	return defaultUnixFlutterPath()
+}
+
+// GetDefaultAndroidSDKPath returns the default Android SDK path
+func (l *LinuxInstaller) GetDefaultAndroidSDKPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Android", "Sdk")
+}
+
+// InstallAndroidSDK installs the command-line tools and SDK packages
+func (l *LinuxInstaller) InstallAndroidSDK(ctx context.Context, progressCallback func(percent int, status string)) error {
+	return installAndroidSDK(ctx, l.Runner, l.Config, progressCallback)
+}
+
+// DownloadFlutter downloads and extracts Flutter SDK
+func (l *LinuxInstaller) DownloadFlutter(ctx context.Context, progressCallback func(percent int, status string)) error {
+	return downloadFlutterRelease(ctx, l.Config, "linux", "x64", progressCallback)
+}
+
+// SetupEnvironmentPath adds Flutter to PATH in the shell startup file
+func (l *LinuxInstaller) SetupEnvironmentPath() (string, error) {
+	return setupUnixEnvironmentPath(l.Config)
+}
+
+// AcceptAndroidLicenses runs flutter doctor --android-licenses
+func (l *LinuxInstaller) AcceptAndroidLicenses(ctx context.Context) error {
+	return acceptAndroidLicenses(ctx, l.Runner)
+}
+
+// AndroidLicenses fetches the licence texts from the Android repository
+func (l *LinuxInstaller) AndroidLicenses() ([]androidsdk.License, error) {
+	return androidLicenses(l.Config)
+}
+
+// WriteAndroidLicenses writes the accepted licence hashes into the SDK
+func (l *LinuxInstaller) WriteAndroidLicenses(licenses []androidsdk.License) error {
+	return writeAndroidLicenses(l.Config, licenses)
+}
+
+// RunFlutterDoctor runs flutter doctor to verify installation
+func (l *LinuxInstaller) RunFlutterDoctor(ctx context.Context) (string, error) {
+	return runFlutterDoctor(ctx, l.Runner)
+}
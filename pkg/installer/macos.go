@@ -0,0 +1,244 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/jdk"
+	"flutter_takeoff/pkg/runner"
+)
+
+// MacOSInstaller handles Flutter installation on macOS
+type MacOSInstaller struct {
+	Config *InstallConfig
+	Runner runner.CommandRunner
+}
+
+var _ Installer = (*MacOSInstaller)(nil)
+
+// NewMacOSInstaller creates a new macOS installer
+func NewMacOSInstaller(config *InstallConfig) *MacOSInstaller {
+	return &MacOSInstaller{Config: config, Runner: runner.Exec{}}
+}
+
+// CheckDependencies checks if required dependencies are installed
+func (m *MacOSInstaller) CheckDependencies(ctx context.Context) []Dependency {
+	return RunProbes(ctx, m.Probes(), nil)
+}
+
+// Probes returns the dependency checks, in the order they are reported
+func (m *MacOSInstaller) Probes() []Probe {
+	return []Probe{
+		{Name: "Git", Check: func(ctx context.Context) Dependency { return checkGit(ctx, m.Runner, m.Config) }},
+		{Name: "Xcode Command Line Tools", Check: m.checkCommandLineTools},
+		{Name: "Xcode", Check: m.checkXcode},
+		{Name: "CocoaPods", Check: m.checkCocoaPods},
+		{Name: "Rosetta 2", Check: m.checkRosetta},
+		{Name: "Java JDK", Check: func(ctx context.Context) Dependency { return checkJava(ctx, m.Runner, m.Config) }},
+		{Name: "Android SDK", Check: m.checkAndroidSDK},
+		{Name: "Flutter SDK", Timeout: flutterTimeout, Check: func(ctx context.Context) Dependency { return checkFlutter(ctx, m.Runner, m.Config) }},
+	}
+}
+
+func (m *MacOSInstaller) checkCommandLineTools(ctx context.Context) Dependency {
+	dep := Dependency{
+		Name:        "Xcode Command Line Tools",
+		Description: "Compilers and git for macOS",
+		Required:    true,
+		Hint:        "xcode-select --install",
+	}
+
+	if result, err := run(ctx, m.Runner, probeTimeout, "xcode-select", "-p"); err == nil {
+		dep.IsInstalled = true
+		dep.Version = strings.TrimSpace(result.Stdout)
+		dep.Path = dep.Version
+	}
+
+	return dep
+}
+
+func (m *MacOSInstaller) checkXcode(ctx context.Context) Dependency {
+	dep := Dependency{
+		Name:        "Xcode",
+		Description: "Full Xcode (required for iOS and macOS development)",
+		Required:    m.Config.Target == TargetIOS,
+		Hint:        "Install Xcode from the App Store, then run: sudo xcode-select --switch /Applications/Xcode.app",
+	}
+
+	// xcodebuild fails when only the command line tools are selected
+	result, err := run(ctx, m.Runner, probeTimeout, "xcodebuild", "-version")
+	if err == nil {
+		dep.IsInstalled = true
+		lines := strings.Split(strings.TrimSpace(result.Stdout), "\n")
+		dep.Version = strings.TrimSpace(lines[0])
+		if path, err := m.Runner.LookPath("xcodebuild"); err == nil {
+			dep.Path = path
+		}
+	}
+
+	return dep
+}
+
+func (m *MacOSInstaller) checkCocoaPods(ctx context.Context) Dependency {
+	dep := Dependency{
+		Name:        "CocoaPods",
+		Description: "Dependency manager for iOS plugins",
+		Required:    m.Config.Target == TargetIOS,
+		Hint:        "brew install cocoapods",
+	}
+
+	if result, err := run(ctx, m.Runner, probeTimeout, "pod", "--version"); err == nil {
+		dep.IsInstalled = true
+		dep.Version = strings.TrimSpace(result.Stdout)
+		if path, err := m.Runner.LookPath("pod"); err == nil {
+			dep.Path = path
+		}
+	}
+
+	return dep
+}
+
+func (m *MacOSInstaller) checkRosetta(ctx context.Context) Dependency {
+	dep := Dependency{
+		Name:        "Rosetta 2",
+		Description: "Intel translation layer (required by parts of the Flutter toolchain on Apple silicon)",
+		Required:    true,
+		Hint:        "softwareupdate --install-rosetta --agree-to-license",
+	}
+
+	// Intel Macs run x86_64 code natively
+	if !m.IsAppleSilicon(ctx) {
+		dep.Required = false
+		dep.Hint = ""
+		dep.Problem = "Not needed on Intel Macs"
+		return dep
+	}
+
+	// Running an x86_64 slice only succeeds when Rosetta is installed
+	if _, err := run(ctx, m.Runner, probeTimeout, "arch", "-x86_64", "/usr/bin/true"); err == nil {
+		dep.IsInstalled = true
+	}
+
+	return dep
+}
+
+func (m *MacOSInstaller) checkAndroidSDK(ctx context.Context) Dependency {
+	return checkAndroidSDKPaths(ctx, m.Runner, m.Config, m.AndroidRequirements(ctx), m.androidSDKPaths())
+}
+
+// androidSDKPaths returns the candidate Android SDK roots, in order
+func (m *MacOSInstaller) androidSDKPaths() []string {
+	home, _ := os.UserHomeDir()
+
+	possiblePaths := []string{
+		os.Getenv("ANDROID_HOME"),
+		os.Getenv("ANDROID_SDK_ROOT"),
+	}
+	if home != "" {
+		possiblePaths = append(possiblePaths, filepath.Join(home, "Library", "Android", "sdk"))
+	}
+
+	return possiblePaths
+}
+
+// AndroidSDKInventory lists the components installed in the Android SDK
+func (m *MacOSInstaller) AndroidSDKInventory() (*androidsdk.Inventory, error) {
+	return androidSDKInventory(m.Config, m.androidSDKPaths())
+}
+
+// AndroidRequirements returns the SDK components the configured Flutter
+// version needs. Apple silicon gets arm64 images even when this binary
+// is an x64 build running under Rosetta
+func (m *MacOSInstaller) AndroidRequirements(ctx context.Context) androidsdk.Requirements {
+	return androidRequirements(m.Config, m.IsAppleSilicon(ctx))
+}
+
+// Emulator manages AVDs with the SDK's avdmanager and emulator
+func (m *MacOSInstaller) Emulator() *Emulator {
+	return &Emulator{Config: m.Config, Runner: m.Runner}
+}
+
+// Flutter switches the channel and version of the Flutter SDK
+func (m *MacOSInstaller) Flutter() *FlutterSDK {
+	return &FlutterSDK{Config: m.Config, Runner: m.Runner, OSName: "macos"}
+}
+
+// IsAppleSilicon reports whether the machine has an arm64 CPU. It asks
+// the kernel rather than runtime.GOARCH, which is amd64 under Rosetta
+func (m *MacOSInstaller) IsAppleSilicon(ctx context.Context) bool {
+	result, err := run(ctx, m.Runner, probeTimeout, "sysctl", "-n", "hw.optional.arm64")
+	return err == nil && strings.TrimSpace(result.Stdout) == "1"
+}
+
+// ArchiveArch returns the architecture of the Flutter zip to download,
+// "arm64" on Apple silicon and "x64" on Intel
+func (m *MacOSInstaller) ArchiveArch(ctx context.Context) string {
+	if m.IsAppleSilicon(ctx) {
+		return "arm64"
+	}
+	return "x64"
+}
+
+// FindJDKs lists the Java installations found on the machine
+func (m *MacOSInstaller) FindJDKs(ctx context.Context) []jdk.Install {
+	return findJDKs(ctx, m.Runner, m.Config)
+}
+
+// SetJavaHome sets JAVA_HOME in the shell startup file
+func (m *MacOSInstaller) SetJavaHome(javaHome string) (string, error) {
+	return setUnixJavaHome(m.Config, javaHome)
+}
+
+// SetFlutterJDK runs flutter config --jdk-dir
+func (m *MacOSInstaller) SetFlutterJDK(ctx context.Context, javaHome string) error {
+	return setFlutterJDK(ctx, m.Runner, m.Config, javaHome)
+}
+
+// GetDefaultFlutterPath returns the default Flutter installation path
+func (m *MacOSInstaller) GetDefaultFlutterPath() string {
+	return defaultUnixFlutterPath()
+}
+
+// GetDefaultAndroidSDKPath returns the default Android SDK path
+func (m *MacOSInstaller) GetDefaultAndroidSDKPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Android", "sdk")
+}
+
+// InstallAndroidSDK installs the command-line tools and SDK packages
+func (m *MacOSInstaller) InstallAndroidSDK(ctx context.Context, progressCallback func(percent int, status string)) error {
+	return installAndroidSDK(ctx, m.Runner, m.Config, progressCallback)
+}
+
+// DownloadFlutter downloads and extracts Flutter SDK
+func (m *MacOSInstaller) DownloadFlutter(ctx context.Context, progressCallback func(percent int, status string)) error {
+	return downloadFlutterRelease(ctx, m.Config, "macos", m.ArchiveArch(ctx), progressCallback)
+}
+
+// SetupEnvironmentPath adds Flutter to PATH in the shell startup file
+func (m *MacOSInstaller) SetupEnvironmentPath() (string, error) {
+	return setupUnixEnvironmentPath(m.Config)
+}
+
+// AcceptAndroidLicenses runs flutter doctor --android-licenses
+func (m *MacOSInstaller) AcceptAndroidLicenses(ctx context.Context) error {
+	return acceptAndroidLicenses(ctx, m.Runner)
+}
+
+// AndroidLicenses fetches the licence texts from the Android repository
+func (m *MacOSInstaller) AndroidLicenses() ([]androidsdk.License, error) {
+	return androidLicenses(m.Config)
+}
+
+// WriteAndroidLicenses writes the accepted licence hashes into the SDK
+func (m *MacOSInstaller) WriteAndroidLicenses(licenses []androidsdk.License) error {
+	return writeAndroidLicenses(m.Config, licenses)
+}
+
+// RunFlutterDoctor runs flutter doctor to verify installation
+func (m *MacOSInstaller) RunFlutterDoctor(ctx context.Context) (string, error) {
+	return runFlutterDoctor(ctx, m.Runner)
+}
@@ -0,0 +1,147 @@
+package installer
+
+import (
+	"context"
+	"testing"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+func findDependency(t *testing.T, deps []Dependency, name string) Dependency {
+	t.Helper()
+	for _, dep := range deps {
+		if dep.Name == name {
+			return dep
+		}
+	}
+	t.Fatalf("dependency %q not reported", name)
+	return Dependency{}
+}
+
+func TestMacOSInstallerChecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		commands    map[string]runner.Result
+		target      TargetPlatform
+		wantArch    string
+		wantRosetta bool
+		installed   map[string]bool
+		versions    map[string]string
+	}{
+		{
+			name: "apple silicon with full xcode",
+			commands: map[string]runner.Result{
+				"sysctl -n hw.optional.arm64": {Stdout: "1\n"},
+				"xcode-select -p":             {Stdout: "/Applications/Xcode.app/Contents/Developer\n"},
+				"xcodebuild -version":         {Stdout: "Xcode 15.4\nBuild version 15F31d\n"},
+				"pod --version":               {Stdout: "1.15.2\n"},
+				"arch -x86_64 /usr/bin/true":  {},
+			},
+			target:      TargetIOS,
+			wantArch:    "arm64",
+			wantRosetta: true,
+			installed: map[string]bool{
+				"Xcode Command Line Tools": true,
+				"Xcode":                    true,
+				"CocoaPods":                true,
+				"Rosetta 2":                true,
+			},
+			versions: map[string]string{
+				"Xcode Command Line Tools": "/Applications/Xcode.app/Contents/Developer",
+				"Xcode":                    "Xcode 15.4",
+				"CocoaPods":                "1.15.2",
+			},
+		},
+		{
+			name: "apple silicon without rosetta",
+			commands: map[string]runner.Result{
+				"sysctl -n hw.optional.arm64": {Stdout: "1\n"},
+				"xcode-select -p":             {Stdout: "/Library/Developer/CommandLineTools\n"},
+				"arch -x86_64 /usr/bin/true":  {ExitCode: 1},
+			},
+			wantArch:    "arm64",
+			wantRosetta: true,
+			installed: map[string]bool{
+				"Xcode Command Line Tools": true,
+				"Xcode":                    false,
+				"CocoaPods":                false,
+				"Rosetta 2":                false,
+			},
+		},
+		{
+			name: "intel with command line tools only",
+			commands: map[string]runner.Result{
+				"sysctl -n hw.optional.arm64": {ExitCode: 1},
+				"xcode-select -p":             {Stdout: "/Library/Developer/CommandLineTools\n"},
+				"xcodebuild -version": {
+					Stderr:   "xcode-select: error: tool 'xcodebuild' requires Xcode\n",
+					ExitCode: 1,
+				},
+			},
+			wantArch: "x64",
+			installed: map[string]bool{
+				"Xcode Command Line Tools": true,
+				"Xcode":                    false,
+				"Rosetta 2":                false,
+			},
+		},
+		{
+			name:     "nothing installed",
+			wantArch: "x64",
+			installed: map[string]bool{
+				"Xcode Command Line Tools": false,
+				"Xcode":                    false,
+				"CocoaPods":                false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &InstallConfig{Platform: PlatformMacOS, Target: tt.target}
+			m := &MacOSInstaller{Config: config, Runner: &runner.Fake{Commands: tt.commands}}
+
+			if got := m.ArchiveArch(context.Background()); got != tt.wantArch {
+				t.Errorf("ArchiveArch() = %q, want %q", got, tt.wantArch)
+			}
+			// The kernel decides, so an x64 build under Rosetta still
+			// suggests arm64 system images
+			wantABI := ""
+			if tt.wantArch == "arm64" {
+				wantABI = "arm64-v8a"
+			}
+			if got := m.AndroidRequirements(context.Background()).SystemImageABI; got != wantABI {
+				t.Errorf("AndroidRequirements().SystemImageABI = %q, want %q", got, wantABI)
+			}
+
+			deps := []Dependency{
+				m.checkCommandLineTools(context.Background()),
+				m.checkXcode(context.Background()),
+				m.checkCocoaPods(context.Background()),
+				m.checkRosetta(context.Background()),
+			}
+
+			for name, want := range tt.installed {
+				dep := findDependency(t, deps, name)
+				if dep.IsInstalled != want {
+					t.Errorf("%s installed = %v, want %v", name, dep.IsInstalled, want)
+				}
+			}
+			for name, want := range tt.versions {
+				if dep := findDependency(t, deps, name); dep.Version != want {
+					t.Errorf("%s version = %q, want %q", name, dep.Version, want)
+				}
+			}
+
+			// Intel Macs report Rosetta as not needed
+			if rosetta := findDependency(t, deps, "Rosetta 2"); rosetta.Required != tt.wantRosetta {
+				t.Errorf("Rosetta 2 required = %v, want %v", rosetta.Required, tt.wantRosetta)
+			}
+
+			xcode := findDependency(t, deps, "Xcode")
+			if xcode.Required != (tt.target == TargetIOS) {
+				t.Errorf("Xcode required = %v for target %q", xcode.Required, tt.target)
+			}
+		})
+	}
+}
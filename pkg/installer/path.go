@@ -0,0 +1,274 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Markers around the blocks written to shell startup files, so re-running
+// a setup step replaces its block instead of adding another
+const (
+	profileBlockStart = "# >>> flutter-takeoff >>>"
+	profileBlockEnd   = "# <<< flutter-takeoff <<<"
+
+	javaBlockStart = "# >>> flutter-takeoff java >>>"
+	javaBlockEnd   = "# <<< flutter-takeoff java <<<"
+)
+
+// UserEnvironment reads and writes the persistent per-user environment,
+// which lives in the registry on Windows
+type UserEnvironment interface {
+	UserPath() (string, error)
+	// SetUserPath stores path and notifies running programs of the change
+	SetUserPath(path string) error
+	// SetUserVariable stores a variable such as JAVA_HOME and notifies
+	// running programs of the change
+	SetUserVariable(name, value string) error
+}
+
+// pathListContains reports whether dir is one of the entries of a PATH
+// style list. Entries are compared after expanding variables and, on
+// Windows, without regard to case or trailing separators
+func pathListContains(list, dir string, separator string, ignoreCase bool) bool {
+	normalize := func(p string) string {
+		p = strings.TrimSpace(os.ExpandEnv(expandPercentVars(p)))
+		p = strings.TrimRight(p, `/\`)
+		if ignoreCase {
+			p = strings.ToLower(p)
+		}
+		return p
+	}
+
+	want := normalize(dir)
+	for _, entry := range strings.Split(list, separator) {
+		if entry != "" && normalize(entry) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// expandPercentVars expands Windows style %VAR% references
+func expandPercentVars(s string) string {
+	for {
+		start := strings.Index(s, "%")
+		if start < 0 {
+			return s
+		}
+		end := strings.Index(s[start+1:], "%")
+		if end < 0 {
+			return s
+		}
+		name := s[start+1 : start+1+end]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return s
+		}
+		s = s[:start] + value + s[start+2+end:]
+	}
+}
+
+// addToUserPath appends binPath to the persistent user PATH unless it is
+// already there or on the current process PATH. It reports whether the
+// user PATH was changed
+func addToUserPath(env UserEnvironment, binPath, processPath string) (bool, error) {
+	if pathListContains(processPath, binPath, ";", true) {
+		return false, nil
+	}
+
+	userPath, err := env.UserPath()
+	if err != nil {
+		return false, fmt.Errorf("failed to read user PATH: %w", err)
+	}
+	if pathListContains(userPath, binPath, ";", true) {
+		return false, nil
+	}
+
+	newPath := binPath
+	if trimmed := strings.TrimRight(userPath, ";"); trimmed != "" {
+		newPath = trimmed + ";" + binPath
+	}
+	if err := env.SetUserPath(newPath); err != nil {
+		return false, fmt.Errorf("failed to update user PATH: %w", err)
+	}
+	return true, nil
+}
+
+// shellProfile returns the startup file of shell (a path such as
+// /bin/zsh) that login and interactive sessions read on goos
+func shellProfile(shell, home, goos string) string {
+	switch filepath.Base(shell) {
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc")
+		}
+		return filepath.Join(home, ".zshrc")
+	case "bash":
+		// Terminal.app starts login shells, which skip .bashrc
+		if goos == "darwin" {
+			return filepath.Join(home, ".bash_profile")
+		}
+		return filepath.Join(home, ".bashrc")
+	case "fish":
+		config := os.Getenv("XDG_CONFIG_HOME")
+		if config == "" {
+			config = filepath.Join(home, ".config")
+		}
+		return filepath.Join(config, "fish", "config.fish")
+	default:
+		return filepath.Join(home, ".profile")
+	}
+}
+
+// profileBlock returns the marked block that puts binPath on PATH
+func profileBlock(shell, binPath string) string {
+	var line string
+	if filepath.Base(shell) == "fish" {
+		line = fmt.Sprintf("set -gx PATH %s $PATH", fishQuote(binPath))
+	} else {
+		line = fmt.Sprintf("export PATH=%s:\"$PATH\"", shellQuote(binPath))
+	}
+	return profileBlockStart + "\n" + line + "\n" + profileBlockEnd + "\n"
+}
+
+// shellQuote quotes s for a POSIX shell. Nothing is special inside
+// single quotes, so a ' closes them, is escaped and opens them again
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// commandQuote quotes s for a command the user pastes into a terminal on
+// platform. cmd.exe has no single quotes, and Windows paths cannot
+// contain "
+func commandQuote(platform Platform, s string) string {
+	if platform == PlatformWindows {
+		return `"` + s + `"`
+	}
+	return shellQuote(s)
+}
+
+// fishQuote quotes s for fish, whose single quotes also take \\ and \'
+// as escapes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// javaProfileBlock returns the marked block that sets JAVA_HOME
+func javaProfileBlock(shell, javaHome string) string {
+	var line string
+	if filepath.Base(shell) == "fish" {
+		line = fmt.Sprintf("set -gx JAVA_HOME %s", fishQuote(javaHome))
+	} else {
+		line = fmt.Sprintf("export JAVA_HOME=%s", shellQuote(javaHome))
+	}
+	return javaBlockStart + "\n" + line + "\n" + javaBlockEnd + "\n"
+}
+
+// updateProfileBlock replaces the block in content that has the same
+// first and last line as block, or appends block when there is none yet
+func updateProfileBlock(content, block string) string {
+	lines := strings.Split(strings.TrimSuffix(block, "\n"), "\n")
+	startMarker, endMarker := lines[0], lines[len(lines)-1]
+
+	start := strings.Index(content, startMarker)
+	if start >= 0 {
+		if end := strings.Index(content[start:], endMarker); end >= 0 {
+			end += start + len(endMarker)
+			if end < len(content) && content[end] == '\n' {
+				end++
+			}
+			return content[:start] + block + content[end:]
+		}
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block
+}
+
+// setupUnixPath writes the PATH block for binPath to the startup file of
+// shell. It returns the file it changed, or "" when binPath is already
+// on processPath or the file is up to date
+func setupUnixPath(binPath, shell, home, processPath string) (string, error) {
+	if pathListContains(processPath, binPath, ":", false) {
+		return "", nil
+	}
+
+	return writeProfileBlock(shellProfile(shell, home, runtime.GOOS), profileBlock(shell, binPath))
+}
+
+// setupUnixJavaHome writes the JAVA_HOME block for javaHome to the
+// startup file of shell. It returns the file it changed, or "" when the
+// file is up to date
+func setupUnixJavaHome(javaHome, shell, home string) (string, error) {
+	return writeProfileBlock(shellProfile(shell, home, runtime.GOOS), javaProfileBlock(shell, javaHome))
+}
+
+// writeProfileBlock adds or replaces block in profile. It returns
+// profile, or "" when the file already contains the block
+func writeProfileBlock(profile, block string) (string, error) {
+	existing, err := os.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", profile, err)
+	}
+
+	updated := updateProfileBlock(string(existing), block)
+	if updated == string(existing) {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(profile), err)
+	}
+	if err := os.WriteFile(profile, []byte(updated), 0644); err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", profile, err)
+	}
+	return profile, nil
+}
+
+// setupUnixEnvironmentPath adds config.FlutterPath/bin to the current
+// user's shell startup file and describes what changed
+func setupUnixEnvironmentPath(config *InstallConfig) (string, error) {
+	binPath := filepath.Join(config.FlutterPath, "bin")
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+
+	profile, err := setupUnixPath(binPath, os.Getenv("SHELL"), home, os.Getenv("PATH"))
+	if err != nil {
+		return "", err
+	}
+	if profile == "" {
+		return binPath + " is already on PATH", nil
+	}
+	return fmt.Sprintf("Added %s to PATH in %s", binPath, profile), nil
+}
+
+// setUnixJavaHome sets JAVA_HOME to javaHome in the current user's shell
+// startup file and in this process, and describes what changed
+func setUnixJavaHome(config *InstallConfig, javaHome string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+
+	profile, err := setupUnixJavaHome(javaHome, os.Getenv("SHELL"), home)
+	if err != nil {
+		return "", err
+	}
+	os.Setenv("JAVA_HOME", javaHome)
+	config.JavaPath = javaHome
+
+	if profile == "" {
+		return "JAVA_HOME is already set to " + javaHome, nil
+	}
+	return fmt.Sprintf("Set JAVA_HOME to %s in %s", javaHome, profile), nil
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package installer
+
+import "errors"
+
+// unsupportedEnvironment is used when the Windows installer runs
+// elsewhere, e.g. in tests, where there is no registry to write to
+type unsupportedEnvironment struct{}
+
+func newUserEnvironment() UserEnvironment {
+	return unsupportedEnvironment{}
+}
+
+func (unsupportedEnvironment) UserPath() (string, error) {
+	return "", errors.New("the user PATH registry value only exists on Windows")
+}
+
+func (unsupportedEnvironment) SetUserPath(string) error {
+	return errors.New("the user PATH registry value only exists on Windows")
+}
+
+func (unsupportedEnvironment) SetUserVariable(string, string) error {
+	return errors.New("user environment registry values only exist on Windows")
+}
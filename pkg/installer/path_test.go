@@ -0,0 +1,271 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// memoryEnvironment keeps the user environment in memory instead of the
+// registry
+type memoryEnvironment struct {
+	path   string
+	vars   map[string]string
+	writes int
+	err    error
+}
+
+func (m *memoryEnvironment) UserPath() (string, error) { return m.path, m.err }
+
+func (m *memoryEnvironment) SetUserPath(path string) error {
+	m.path = path
+	m.writes++
+	return nil
+}
+
+func (m *memoryEnvironment) SetUserVariable(name, value string) error {
+	if m.vars == nil {
+		m.vars = make(map[string]string)
+	}
+	m.vars[name] = value
+	m.writes++
+	return nil
+}
+
+func TestAddToUserPath(t *testing.T) {
+	t.Setenv("USERPROFILE", `C:\Users\dev`)
+
+	tests := []struct {
+		name        string
+		userPath    string
+		processPath string
+		want        string
+		wantChanged bool
+	}{
+		{
+			name:        "empty",
+			want:        `C:\Users\dev\flutter\bin`,
+			wantChanged: true,
+		},
+		{
+			name:        "appended",
+			userPath:    `C:\tools;`,
+			want:        `C:\tools;C:\Users\dev\flutter\bin`,
+			wantChanged: true,
+		},
+		{
+			name:     "already present with different case and trailing slash",
+			userPath: `C:\tools;c:\users\dev\FLUTTER\bin\`,
+			want:     `C:\tools;c:\users\dev\FLUTTER\bin\`,
+		},
+		{
+			name:     "already present as a variable",
+			userPath: `%USERPROFILE%\flutter\bin`,
+			want:     `%USERPROFILE%\flutter\bin`,
+		},
+		{
+			name:        "on the machine PATH",
+			userPath:    `C:\tools`,
+			processPath: `C:\Windows;C:\Users\dev\flutter\bin`,
+			want:        `C:\tools`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &memoryEnvironment{path: tt.userPath}
+			changed, err := addToUserPath(env, `C:\Users\dev\flutter\bin`, tt.processPath)
+			if err != nil {
+				t.Fatalf("addToUserPath() error = %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if env.path != tt.want {
+				t.Errorf("user PATH = %q, want %q", env.path, tt.want)
+			}
+
+			// Running again never adds a second entry
+			if _, err := addToUserPath(env, `C:\Users\dev\flutter\bin`, tt.processPath); err != nil {
+				t.Fatal(err)
+			}
+			if env.path != tt.want {
+				t.Errorf("second run changed user PATH to %q", env.path)
+			}
+		})
+	}
+}
+
+func TestAddToUserPathReadError(t *testing.T) {
+	env := &memoryEnvironment{err: errors.New("access denied")}
+	if _, err := addToUserPath(env, `C:\flutter\bin`, ""); err == nil {
+		t.Fatal("addToUserPath() should fail when the registry cannot be read")
+	}
+	if env.writes != 0 {
+		t.Error("nothing should be written after a read error")
+	}
+}
+
+func TestShellProfile(t *testing.T) {
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	tests := []struct {
+		shell, goos, want string
+	}{
+		{"/bin/bash", "linux", "/home/dev/.bashrc"},
+		{"/bin/bash", "darwin", "/home/dev/.bash_profile"},
+		{"/bin/zsh", "darwin", "/home/dev/.zshrc"},
+		{"/usr/bin/fish", "linux", "/home/dev/.config/fish/config.fish"},
+		{"/bin/sh", "linux", "/home/dev/.profile"},
+		{"", "linux", "/home/dev/.profile"},
+	}
+	for _, tt := range tests {
+		if got := shellProfile(tt.shell, "/home/dev", tt.goos); got != filepath.FromSlash(tt.want) {
+			t.Errorf("shellProfile(%q, %q) = %q, want %q", tt.shell, tt.goos, got, tt.want)
+		}
+	}
+}
+
+func TestSetupUnixPath(t *testing.T) {
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	for _, shell := range []string{"/bin/bash", "/bin/zsh", "/usr/bin/fish"} {
+		t.Run(filepath.Base(shell), func(t *testing.T) {
+			home := t.TempDir()
+			profile := shellProfile(shell, home, runtime.GOOS)
+			if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(profile, []byte("alias ll='ls -l'"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			changed, err := setupUnixPath("/opt/flutter/bin", shell, home, "/usr/bin:/bin")
+			if err != nil {
+				t.Fatalf("setupUnixPath() error = %v", err)
+			}
+			if changed != profile {
+				t.Errorf("changed %q, want %q", changed, profile)
+			}
+
+			// A second run with the same path leaves the file alone
+			if again, err := setupUnixPath("/opt/flutter/bin", shell, home, "/usr/bin:/bin"); err != nil || again != "" {
+				t.Errorf("second run = %q, %v, want no change", again, err)
+			}
+
+			// Moving the SDK replaces the block rather than adding another
+			if _, err := setupUnixPath("/home/dev/flutter/bin", shell, home, "/usr/bin:/bin"); err != nil {
+				t.Fatal(err)
+			}
+
+			content, _ := os.ReadFile(changed)
+			text := string(content)
+			if !strings.HasPrefix(text, "alias ll='ls -l'\n") {
+				t.Errorf("existing content not preserved:\n%s", text)
+			}
+			if strings.Count(text, profileBlockStart) != 1 || strings.Count(text, profileBlockEnd) != 1 {
+				t.Errorf("want exactly one marked block:\n%s", text)
+			}
+			if strings.Contains(text, "/opt/flutter/bin") || !strings.Contains(text, `'/home/dev/flutter/bin'`) {
+				t.Errorf("block not updated to the new path:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestProfileBlockQuoting(t *testing.T) {
+	const dir = `/home/dev/it's $HOME/` + "`id`" + `\flutter/bin`
+
+	if got, want := profileBlock("/usr/bin/fish", dir), `set -gx PATH '/home/dev/it\'s $HOME/`+"`id`"+`\\flutter/bin' $PATH`; !strings.Contains(got, want) {
+		t.Errorf("fish block = %q, want it to contain %q", got, want)
+	}
+	if got, want := javaProfileBlock("/usr/bin/fish", dir), `set -gx JAVA_HOME '/home/dev/it\'s $HOME/`+"`id`"+`\\flutter/bin'`; !strings.Contains(got, want) {
+		t.Errorf("fish JAVA_HOME block = %q, want it to contain %q", got, want)
+	}
+
+	sh, err := exec.LookPath("sh")
+	if err != nil || runtime.GOOS == "windows" {
+		t.Skip("no POSIX shell")
+	}
+	// The shell must read the paths back exactly, without expanding them
+	script := profileBlock("/bin/bash", dir) + javaProfileBlock("/bin/bash", dir) + `printf '%s\n%s' "$PATH" "$JAVA_HOME"`
+	cmd := exec.Command(sh, "-c", script)
+	cmd.Env = []string{"PATH=/usr/bin", "HOME=/home/dev"}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sh error = %v", err)
+	}
+	if want := dir + ":/usr/bin\n" + dir; string(out) != want {
+		t.Errorf("PATH and JAVA_HOME = %q, want %q", out, want)
+	}
+}
+
+func TestSetupUnixPathAlreadyOnPath(t *testing.T) {
+	home := t.TempDir()
+	changed, err := setupUnixPath("/opt/flutter/bin", "/bin/bash", home, "/usr/bin:/opt/flutter/bin/")
+	if err != nil || changed != "" {
+		t.Fatalf("setupUnixPath() = %q, %v, want no change", changed, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".bashrc")); !os.IsNotExist(err) {
+		t.Error("no startup file should be created when flutter is already on PATH")
+	}
+}
+
+func TestUpdateProfileBlock(t *testing.T) {
+	block := profileBlock("/bin/bash", "/opt/flutter/bin")
+
+	if got := updateProfileBlock("", block); got != block {
+		t.Errorf("empty file = %q", got)
+	}
+
+	content := "export A=1\n\n" + profileBlock("/bin/bash", "/old/bin") + "export B=2\n"
+	want := "export A=1\n\n" + block + "export B=2\n"
+	if got := updateProfileBlock(content, block); got != want {
+		t.Errorf("replace = %q, want %q", got, want)
+	}
+}
+
+func TestSetupUnixJavaHome(t *testing.T) {
+	t.Setenv("ZDOTDIR", "")
+	home := t.TempDir()
+
+	if _, err := setupUnixPath("/opt/flutter/bin", "/bin/zsh", home, "/usr/bin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := setupUnixJavaHome("/usr/lib/jvm/java-11", "/bin/zsh", home); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := setupUnixJavaHome("/usr/lib/jvm/temurin-17", "/bin/zsh", home)
+	if err != nil || changed != filepath.Join(home, ".zshrc") {
+		t.Fatalf("setupUnixJavaHome() = %q, %v", changed, err)
+	}
+
+	// The JAVA_HOME block is replaced on its own, next to the PATH block
+	content, _ := os.ReadFile(changed)
+	want := profileBlock("/bin/zsh", "/opt/flutter/bin") + "\n" + javaProfileBlock("/bin/zsh", "/usr/lib/jvm/temurin-17")
+	if string(content) != want {
+		t.Errorf(".zshrc = %q, want %q", content, want)
+	}
+}
+
+func TestWindowsSetJavaHome(t *testing.T) {
+	t.Setenv("JAVA_HOME", "")
+	env := &memoryEnvironment{}
+	w := &WindowsInstaller{Config: &InstallConfig{Platform: PlatformWindows}, Env: env}
+
+	if msg, err := w.SetJavaHome(`C:\Program Files\Android\Android Studio\jbr`); err != nil || !strings.Contains(msg, "Set JAVA_HOME") {
+		t.Fatalf("SetJavaHome() = %q, %v", msg, err)
+	}
+	if got := env.vars["JAVA_HOME"]; got != `C:\Program Files\Android\Android Studio\jbr` {
+		t.Errorf("user JAVA_HOME = %q", got)
+	}
+	if os.Getenv("JAVA_HOME") != w.Config.JavaPath || w.Config.JavaPath != env.vars["JAVA_HOME"] {
+		t.Errorf("process JAVA_HOME = %q, config.JavaPath = %q", os.Getenv("JAVA_HOME"), w.Config.JavaPath)
+	}
+}
@@ -0,0 +1,87 @@
+package installer
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// registryEnvironment stores the user PATH in HKCU\Environment
+type registryEnvironment struct{}
+
+func newUserEnvironment() UserEnvironment {
+	return registryEnvironment{}
+}
+
+func (registryEnvironment) UserPath() (string, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue("Path")
+	if err == registry.ErrNotExist {
+		return "", nil
+	}
+	return value, err
+}
+
+func (registryEnvironment) SetUserPath(path string) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	// REG_EXPAND_SZ keeps entries such as %USERPROFILE%\bin working
+	if err := key.SetExpandStringValue("Path", path); err != nil {
+		return err
+	}
+	return broadcastEnvironmentChange()
+}
+
+func (registryEnvironment) SetUserVariable(name, value string) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	if err := key.SetStringValue(name, value); err != nil {
+		return err
+	}
+	return broadcastEnvironmentChange()
+}
+
+// broadcastEnvironmentChange tells Explorer and other top-level windows to
+// reload the environment, so new terminals see the updated PATH
+func broadcastEnvironmentChange() error {
+	const (
+		hwndBroadcast   = 0xffff
+		wmSettingChange = 0x001A
+		smtoAbortIfHung = 0x0002
+	)
+
+	param, err := windows.UTF16PtrFromString("Environment")
+	if err != nil {
+		return err
+	}
+
+	proc := windows.NewLazySystemDLL("user32.dll").NewProc("SendMessageTimeoutW")
+	var result uintptr
+	ret, _, err := proc.Call(
+		hwndBroadcast,
+		wmSettingChange,
+		0,
+		uintptr(unsafe.Pointer(param)),
+		smtoAbortIfHung,
+		5000,
+		uintptr(unsafe.Pointer(&result)),
+	)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package installer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Probe checks a single dependency
+type Probe struct {
+	// Name is the Dependency.Name the probe reports
+	Name string
+	// Timeout bounds the whole probe; zero means probeTimeout
+	Timeout time.Duration
+	Check   func(ctx context.Context) Dependency
+}
+
+// RunProbes runs the probes concurrently, each under its own timeout,
+// and returns their results in the order of probes. done, when not nil,
+// is called from the probe's goroutine as soon as each probe finishes
+func RunProbes(ctx context.Context, probes []Probe, done func(i int, dep Dependency)) []Dependency {
+	deps := make([]Dependency, len(probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			timeout := probe.Timeout
+			if timeout == 0 {
+				timeout = probeTimeout
+			}
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			deps[i] = probe.Check(probeCtx)
+			if done != nil {
+				done(i, deps[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return deps
+}
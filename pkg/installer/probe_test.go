@@ -0,0 +1,95 @@
+package installer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+func TestRunProbesConcurrently(t *testing.T) {
+	delays := []time.Duration{60 * time.Millisecond, 10 * time.Millisecond, 30 * time.Millisecond}
+
+	var probes []Probe
+	for i, d := range delays {
+		name := string(rune('A' + i))
+		probes = append(probes, Probe{Name: name, Check: func(ctx context.Context) Dependency {
+			time.Sleep(d)
+			return Dependency{Name: name, IsInstalled: true}
+		}})
+	}
+
+	var mu sync.Mutex
+	var finished []int
+	start := time.Now()
+	deps := RunProbes(context.Background(), probes, func(i int, dep Dependency) {
+		mu.Lock()
+		defer mu.Unlock()
+		finished = append(finished, i)
+	})
+	elapsed := time.Since(start)
+
+	// Results keep the probe order however they finish
+	for i, dep := range deps {
+		if dep.Name != probes[i].Name {
+			t.Errorf("deps[%d] = %q, want %q", i, dep.Name, probes[i].Name)
+		}
+	}
+	if len(finished) != len(probes) || finished[0] != 1 || finished[len(finished)-1] != 0 {
+		t.Errorf("probes finished in order %v, want the fastest first", finished)
+	}
+	if elapsed >= 100*time.Millisecond {
+		t.Errorf("RunProbes took %v; the probes did not run concurrently", elapsed)
+	}
+}
+
+func TestRunProbesTimeout(t *testing.T) {
+	probes := []Probe{
+		{Name: "slow", Timeout: 10 * time.Millisecond, Check: func(ctx context.Context) Dependency {
+			<-ctx.Done()
+			return Dependency{Name: "slow"}
+		}},
+		{Name: "fast", Check: func(ctx context.Context) Dependency {
+			return Dependency{Name: "fast", IsInstalled: ctx.Err() == nil}
+		}},
+	}
+
+	deps := RunProbes(context.Background(), probes, nil)
+	if deps[0].IsInstalled || !deps[1].IsInstalled {
+		t.Errorf("RunProbes = %+v; the timeout of one probe should not affect the other", deps)
+	}
+}
+
+// TestProbeNames checks that every installer's probes report under the
+// name they announce, which the live checklist relies on
+func TestProbeNames(t *testing.T) {
+	t.Setenv("ANDROID_HOME", "")
+	t.Setenv("ANDROID_SDK_ROOT", "")
+
+	fake := runner.NewFake().On("sysctl -n hw.optional.arm64", runner.Result{Stdout: "1\n"})
+	installers := map[string]Installer{
+		"windows": &WindowsInstaller{Config: &InstallConfig{}, Runner: fake},
+		"linux":   &LinuxInstaller{Config: &InstallConfig{}, Runner: fake},
+		"macos":   &MacOSInstaller{Config: &InstallConfig{}, Runner: fake},
+	}
+
+	for name, inst := range installers {
+		// Building the list runs nothing; all work happens in the checks
+		before := len(fake.Calls())
+		probes := inst.Probes()
+		if calls := fake.Calls()[before:]; len(calls) != 0 {
+			t.Errorf("%s: Probes() ran %q", name, calls)
+		}
+		deps := inst.CheckDependencies(context.Background())
+		if len(deps) != len(probes) {
+			t.Fatalf("%s: %d results for %d probes", name, len(deps), len(probes))
+		}
+		for i, dep := range deps {
+			if dep.Name != probes[i].Name {
+				t.Errorf("%s: probe %q reported %q", name, probes[i].Name, dep.Name)
+			}
+		}
+	}
+}
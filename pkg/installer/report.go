@@ -0,0 +1,48 @@
+package installer
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ReportSchemaVersion is bumped whenever a field of DependencyReport or
+// Dependency is renamed, removed or changes meaning. Adding fields does
+// not change it
+const ReportSchemaVersion = 1
+
+// DependencyReport is the machine-readable result of CheckDependencies
+type DependencyReport struct {
+	SchemaVersion int      `json:"schema_version"`
+	Platform      Platform `json:"platform"`
+	// Ready is true when every required dependency is installed
+	Ready        bool         `json:"ready"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// NewDependencyReport wraps the result of CheckDependencies
+func NewDependencyReport(platform Platform, deps []Dependency) DependencyReport {
+	report := DependencyReport{
+		SchemaVersion: ReportSchemaVersion,
+		Platform:      platform,
+		Ready:         true,
+		Dependencies:  deps,
+	}
+	if report.Dependencies == nil {
+		report.Dependencies = []Dependency{}
+	}
+
+	for _, dep := range deps {
+		if dep.Required && !dep.IsInstalled {
+			report.Ready = false
+			break
+		}
+	}
+	return report
+}
+
+// WriteJSON writes the report as indented JSON
+func (r DependencyReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,84 @@
+package installer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDependencyReportJSON(t *testing.T) {
+	deps := []Dependency{
+		{
+			Name:        "Git",
+			Description: "Version control system (required for Flutter)",
+			IsInstalled: true,
+			Version:     "git version 2.45.1",
+			Required:    true,
+			Path:        "/usr/bin/git",
+			Hint:        "Install git with your package manager, e.g. sudo apt-get install git",
+		},
+		{
+			Name:        "Flutter SDK",
+			Description: "Flutter development framework",
+			Hint:        "Run 'flutter-takeoff install' to download the Flutter SDK",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewDependencyReport(PlatformLinux, deps).WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Consumers depend on this exact layout; renaming or removing a field means bumping ReportSchemaVersion
+	want := `{
+  "schema_version": 1,
+  "platform": "linux",
+  "ready": true,
+  "dependencies": [
+    {
+      "name": "Git",
+      "description": "Version control system (required for Flutter)",
+      "installed": true,
+      "version": "git version 2.45.1",
+      "required": true,
+      "path": "/usr/bin/git",
+      "hint": "Install git with your package manager, e.g. sudo apt-get install git",
+      "problem": ""
+    },
+    {
+      "name": "Flutter SDK",
+      "description": "Flutter development framework",
+      "installed": false,
+      "version": "",
+      "required": false,
+      "path": "",
+      "hint": "Run 'flutter-takeoff install' to download the Flutter SDK",
+      "problem": ""
+    }
+  ]
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("JSON =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDependencyReportReady(t *testing.T) {
+	tests := []struct {
+		name string
+		deps []Dependency
+		want bool
+	}{
+		{"no dependencies", nil, true},
+		{"optional missing", []Dependency{{Required: true, IsInstalled: true}, {Required: false}}, true},
+		{"required missing", []Dependency{{Required: true, IsInstalled: true}, {Required: true}}, false},
+	}
+	for _, tt := range tests {
+		report := NewDependencyReport(PlatformWindows, tt.deps)
+		if report.Ready != tt.want {
+			t.Errorf("%s: Ready = %v, want %v", tt.name, report.Ready, tt.want)
+		}
+		if report.Dependencies == nil {
+			t.Errorf("%s: Dependencies must encode as [] rather than null", tt.name)
+		}
+	}
+}
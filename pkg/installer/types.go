@@ -19,13 +19,21 @@ const (
 	TargetDesktop TargetPlatform = "desktop"
 )
 
-// Dependency represents a required software dependency
+// Dependency represents a required software dependency. The JSON field
+// names are part of the DependencyReport schema
 type Dependency struct {
-	Name        string
-	Description string
-	IsInstalled bool
-	Version     string
-	Required    bool
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsInstalled bool   `json:"installed"`
+	Version     string `json:"version"`
+	Required    bool   `json:"required"`
+	// Path is where the dependency was found, if anywhere
+	Path string `json:"path"`
+	// Hint tells the user how to install the dependency
+	Hint string `json:"hint"`
+	// Problem explains why a dependency that was found is unusable, or
+	// warns about it when it is still installed
+	Problem string `json:"problem"`
 }
 
 // InstallConfig holds configuration for the installation
@@ -36,4 +44,31 @@ type InstallConfig struct {
 	JavaPath       string
 	Platform       Platform
 	Target         TargetPlatform
+
+	// FlutterVersion and Channel select the release to download.
+	// FlutterVersion is "latest", a release line such as "3.22.x" or an
+	// exact version
+	FlutterVersion string
+	Channel        string
+	// StorageBaseURL overrides the host Flutter releases are downloaded
+	// from, e.g. an internal mirror. Defaults to $FLUTTER_STORAGE_BASE_URL
+	// and then DefaultStorageBaseURL
+	StorageBaseURL string
+	// DownloadDir caches SDK archives, including partial downloads that
+	// are resumed on the next run. Defaults to <user cache>/flutter-takeoff/downloads
+	DownloadDir string
+	// ArchivePath is where DownloadFlutter saved the SDK archive. It is
+	// kept in DownloadDir after extraction so reinstalls skip the download
+	ArchivePath string
+
+	// AndroidPackages are the sdkmanager packages InstallAndroidSDK
+	// installs, e.g. "platforms;android-34". Defaults to DefaultAndroidPackages
+	AndroidPackages []string
+	// AndroidRepositoryURL overrides the repository the command-line tools
+	// are downloaded from. Defaults to androidsdk.DefaultRepositoryURL
+	AndroidRepositoryURL string
+	// AcceptAndroidLicenses makes InstallAndroidSDK accept the licences in
+	// the Android repository before running sdkmanager, which otherwise
+	// stops to ask about each one
+	AcceptAndroidLicenses bool
 }
@@ -1,88 +1,85 @@
 package installer
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
+	"runtime"
+
+	"flutter_takeoff/pkg/androidsdk"
+	"flutter_takeoff/pkg/jdk"
+	"flutter_takeoff/pkg/runner"
 )
 
 // WindowsInstaller handles Flutter installation on Windows
 type WindowsInstaller struct {
 	Config *InstallConfig
+	Runner runner.CommandRunner
+	Env    UserEnvironment
 }
 
+var _ Installer = (*WindowsInstaller)(nil)
+
 // NewWindowsInstaller creates a new Windows installer
 func NewWindowsInstaller(config *InstallConfig) *WindowsInstaller {
-	return &WindowsInstaller{Config: config}
+	return &WindowsInstaller{Config: config, Runner: runner.Exec{}, Env: newUserEnvironment()}
 }
 
 // CheckDependencies checks if required dependencies are installed
-func (w *WindowsInstaller) CheckDependencies() []Dependency {
-	deps := []Dependency{
-		w.checkGit(),
-		w.checkJava(),
-		w.checkAndroidSDK(),
-		w.checkFlutter(),
-	}
-	return deps
+func (w *WindowsInstaller) CheckDependencies(ctx context.Context) []Dependency {
+	return RunProbes(ctx, w.Probes(), nil)
 }
 
-func (w *WindowsInstaller) checkGit() Dependency {
-	dep := Dependency{
-		Name:        "Git",
-		Description: "Version control system (required for Flutter)",
-		Required:    true,
-	}
-
-	cmd := exec.Command("git", "--version")
-	output, err := cmd.CombinedOutput()
-	if err == nil {
-		dep.IsInstalled = true
-		dep.Version = strings.TrimSpace(string(output))
-	}
-
-	// Try to find git path
-	gitPath, err := exec.LookPath("git")
-	if err == nil {
-		w.Config.GitPath = gitPath
+// Probes returns the dependency checks, in the order they are reported
+func (w *WindowsInstaller) Probes() []Probe {
+	return []Probe{
+		{Name: "Git", Check: func(ctx context.Context) Dependency { return checkGit(ctx, w.Runner, w.Config) }},
+		{Name: "Java JDK", Check: func(ctx context.Context) Dependency { return checkJava(ctx, w.Runner, w.Config) }},
+		{Name: "Android SDK", Check: w.checkAndroidSDK},
+		{Name: "Visual Studio", Check: w.checkVisualStudio},
+		{Name: "Flutter SDK", Timeout: flutterTimeout, Check: func(ctx context.Context) Dependency { return checkFlutter(ctx, w.Runner, w.Config) }},
 	}
-
-	return dep
 }
 
-func (w *WindowsInstaller) checkJava() Dependency {
+// checkVisualStudio looks for a Visual Studio with the C++ desktop
+// workload, which flutter needs to build Windows desktop apps, using the
+// vswhere tool the Visual Studio installer leaves behind
+func (w *WindowsInstaller) checkVisualStudio(ctx context.Context) Dependency {
 	dep := Dependency{
-		Name:        "Java JDK",
-		Description: "Java Development Kit 17+ (required for Android development)",
-		Required:    true,
+		Name:        "Visual Studio",
+		Description: "MSVC C++ toolchain (required for Windows desktop)",
+		Required:    w.Config.Target == TargetDesktop,
+		Hint:        `Install Visual Studio with the "Desktop development with C++" workload`,
 	}
 
-	cmd := exec.Command("java", "-version")
-	output, err := cmd.CombinedOutput()
-	if err == nil {
-		dep.IsInstalled = true
-		dep.Version = strings.TrimSpace(string(output))
+	vswhere := filepath.Join(os.Getenv("ProgramFiles(x86)"), "Microsoft Visual Studio", "Installer", "vswhere.exe")
+	result, err := run(ctx, w.Runner, probeTimeout, vswhere, "-latest", "-products", "*",
+		"-requires", "Microsoft.VisualStudio.Component.VC.Tools.x86.x64", "-format", "json")
+	if err != nil {
+		return dep
 	}
-
-	// Try to find JAVA_HOME
-	javaHome := os.Getenv("JAVA_HOME")
-	if javaHome != "" {
-		w.Config.JavaPath = javaHome
+	var found []struct {
+		DisplayName      string `json:"displayName"`
+		InstallationPath string `json:"installationPath"`
+	}
+	if err := json.Unmarshal([]byte(result.Stdout), &found); err != nil || len(found) == 0 {
+		return dep
 	}
 
+	dep.IsInstalled = true
+	dep.Version = found[0].DisplayName
+	dep.Path = found[0].InstallationPath
 	return dep
 }
 
-func (w *WindowsInstaller) checkAndroidSDK() Dependency {
-	dep := Dependency{
-		Name:        "Android SDK",
-		Description: "Android command-line tools (required for Android development)",
-		Required:    true,
-	}
+func (w *WindowsInstaller) checkAndroidSDK(ctx context.Context) Dependency {
+	return checkAndroidSDKPaths(ctx, w.Runner, w.Config, w.AndroidRequirements(ctx), w.androidSDKPaths())
+}
 
-	// Check common locations
+// androidSDKPaths returns the candidate Android SDK roots, in order
+func (w *WindowsInstaller) androidSDKPaths() []string {
 	possiblePaths := []string{
 		os.Getenv("ANDROID_HOME"),
 		os.Getenv("ANDROID_SDK_ROOT"),
@@ -90,51 +87,48 @@ func (w *WindowsInstaller) checkAndroidSDK() Dependency {
 		filepath.Join(os.Getenv("USERPROFILE"), "AppData", "Local", "Android", "Sdk"),
 	}
 
-	for _, path := range possiblePaths {
-		if path != "" {
-			if _, err := os.Stat(filepath.Join(path, "platform-tools")); err == nil {
-				dep.IsInstalled = true
-				w.Config.AndroidSDKPath = path
-
-				// Try to get version
-				adbPath := filepath.Join(path, "platform-tools", "adb.exe")
-				if cmd := exec.Command(adbPath, "version"); cmd != nil {
-					if output, err := cmd.CombinedOutput(); err == nil {
-						dep.Version = strings.Split(string(output), "\n")[0]
-					}
-				}
-				break
-			}
-		}
-	}
+	return possiblePaths
+}
 
-	return dep
+// AndroidSDKInventory lists the components installed in the Android SDK
+func (w *WindowsInstaller) AndroidSDKInventory() (*androidsdk.Inventory, error) {
+	return androidSDKInventory(w.Config, w.androidSDKPaths())
 }
 
-func (w *WindowsInstaller) checkFlutter() Dependency {
-	dep := Dependency{
-		Name:        "Flutter SDK",
-		Description: "Flutter development framework",
-		Required:    false,
-	}
+// AndroidRequirements returns the SDK components the configured Flutter
+// version needs
+func (w *WindowsInstaller) AndroidRequirements(ctx context.Context) androidsdk.Requirements {
+	return androidRequirements(w.Config, runtime.GOARCH == "arm64")
+}
 
-	cmd := exec.Command("flutter", "--version")
-	output, err := cmd.CombinedOutput()
-	if err == nil {
-		dep.IsInstalled = true
-		lines := strings.Split(string(output), "\n")
-		if len(lines) > 0 {
-			dep.Version = strings.TrimSpace(lines[0])
-		}
-	}
+// Emulator manages AVDs with the SDK's avdmanager and emulator
+func (w *WindowsInstaller) Emulator() *Emulator {
+	return &Emulator{Config: w.Config, Runner: w.Runner}
+}
+
+// Flutter switches the channel and version of the Flutter SDK
+func (w *WindowsInstaller) Flutter() *FlutterSDK {
+	return &FlutterSDK{Config: w.Config, Runner: w.Runner, OSName: "windows"}
+}
 
-	// Try to find flutter path
-	flutterPath, err := exec.LookPath("flutter")
-	if err == nil {
-		w.Config.FlutterPath = filepath.Dir(filepath.Dir(flutterPath))
+// FindJDKs lists the Java installations found on the machine
+func (w *WindowsInstaller) FindJDKs(ctx context.Context) []jdk.Install {
+	return findJDKs(ctx, w.Runner, w.Config)
+}
+
+// SetJavaHome sets JAVA_HOME in the user environment in the registry
+func (w *WindowsInstaller) SetJavaHome(javaHome string) (string, error) {
+	if err := w.Env.SetUserVariable("JAVA_HOME", javaHome); err != nil {
+		return "", fmt.Errorf("failed to set JAVA_HOME: %w", err)
 	}
+	os.Setenv("JAVA_HOME", javaHome)
+	w.Config.JavaPath = javaHome
+	return "Set JAVA_HOME to " + javaHome, nil
+}
 
-	return dep
+// SetFlutterJDK runs flutter config --jdk-dir
+func (w *WindowsInstaller) SetFlutterJDK(ctx context.Context, javaHome string) error {
+	return setFlutterJDK(ctx, w.Runner, w.Config, javaHome)
 }
 
 // GetDefaultFlutterPath returns the default Flutter installation path
@@ -149,44 +143,46 @@ func (w *WindowsInstaller) GetDefaultAndroidSDKPath() string {
 	return filepath.Join(localAppData, "Android", "Sdk")
 }
 
-// DownloadFlutter downloads and extracts Flutter SDK
-func (w *WindowsInstaller) DownloadFlutter(progressCallback func(percent int, status string)) error {
-	// This is a placeholder - actual implementation would download from
-	// https://storage.googleapis.com/flutter_infra_release/releases/stable/windows/flutter_windows_3.x.x-stable.zip
-	progressCallback(0, "Preparing to download Flutter SDK...")
-
-	// Check if path exists
-	if _, err := os.Stat(w.Config.FlutterPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(w.Config.FlutterPath, 0755); err != nil {
-			return fmt.Errorf("failed to create Flutter directory: %w", err)
-		}
-	}
+// InstallAndroidSDK installs the command-line tools and SDK packages
+func (w *WindowsInstaller) InstallAndroidSDK(ctx context.Context, progressCallback func(percent int, status string)) error {
+	return installAndroidSDK(ctx, w.Runner, w.Config, progressCallback)
+}
 
-	progressCallback(100, "Flutter download complete!")
-	return nil
+// DownloadFlutter downloads and extracts Flutter SDK
+func (w *WindowsInstaller) DownloadFlutter(ctx context.Context, progressCallback func(percent int, status string)) error {
+	return downloadFlutterRelease(ctx, w.Config, "windows", "x64", progressCallback)
 }
 
-// SetupEnvironmentPath adds Flutter to PATH
-func (w *WindowsInstaller) SetupEnvironmentPath() error {
-	// This would use PowerShell to permanently add to PATH
-	// For now, just a placeholder
+// SetupEnvironmentPath adds Flutter to the user PATH in the registry
+func (w *WindowsInstaller) SetupEnvironmentPath() (string, error) {
 	binPath := filepath.Join(w.Config.FlutterPath, "bin")
-	fmt.Printf("Add to PATH: %s\n", binPath)
-	return nil
+
+	changed, err := addToUserPath(w.Env, binPath, os.Getenv("PATH"))
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return binPath + " is already on PATH", nil
+	}
+	return "Added " + binPath + " to the user PATH", nil
 }
 
 // AcceptAndroidLicenses runs flutter doctor --android-licenses
-func (w *WindowsInstaller) AcceptAndroidLicenses() error {
-	cmd := exec.Command("flutter", "doctor", "--android-licenses")
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+func (w *WindowsInstaller) AcceptAndroidLicenses(ctx context.Context) error {
+	return acceptAndroidLicenses(ctx, w.Runner)
+}
+
+// AndroidLicenses fetches the licence texts from the Android repository
+func (w *WindowsInstaller) AndroidLicenses() ([]androidsdk.License, error) {
+	return androidLicenses(w.Config)
+}
+
+// WriteAndroidLicenses writes the accepted licence hashes into the SDK
+func (w *WindowsInstaller) WriteAndroidLicenses(licenses []androidsdk.License) error {
+	return writeAndroidLicenses(w.Config, licenses)
 }
 
 // RunFlutterDoctor runs flutter doctor to verify installation
-func (w *WindowsInstaller) RunFlutterDoctor() (string, error) {
-	cmd := exec.Command("flutter", "doctor", "-v")
-	output, err := cmd.CombinedOutput()
-	return string(output), err
+func (w *WindowsInstaller) RunFlutterDoctor(ctx context.Context) (string, error) {
+	return runFlutterDoctor(ctx, w.Runner)
 }
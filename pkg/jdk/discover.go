@@ -0,0 +1,208 @@
+package jdk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+// versionTimeout bounds each `java -version` run during discovery
+const versionTimeout = 10 * time.Second
+
+// Location is a place JDKs are installed. Pattern is a JDK home or a
+// glob matching several, e.g. /usr/lib/jvm/*
+type Location struct {
+	Pattern string
+	// Source says where the JDK came from, e.g. "Android Studio"
+	Source string
+}
+
+// Install is a Java installation found on the machine
+type Install struct {
+	Home   string
+	Source string
+	Info   Info
+	// HasJavac is false for runtimes (JREs) that cannot compile
+	HasJavac bool
+}
+
+// Usable reports whether Flutter can build Android apps with the install
+func (i Install) Usable() bool {
+	return i.HasJavac && i.Info.Supported()
+}
+
+// Locations returns where JDKs are usually installed on goos, in the
+// order they should be listed: JAVA_HOME, Android Studio's bundled JBR,
+// then vendor and package manager directories
+func Locations(goos, home string, getenv func(string) string) []Location {
+	var locs []Location
+	if javaHome := getenv("JAVA_HOME"); javaHome != "" {
+		locs = append(locs, Location{javaHome, "JAVA_HOME"})
+	}
+
+	switch goos {
+	case "windows":
+		programFiles := getenv("ProgramFiles")
+		if programFiles == "" {
+			programFiles = `C:\Program Files`
+		}
+		for _, studio := range []string{
+			filepath.Join(programFiles, "Android", "Android Studio"),
+			filepath.Join(getenv("LOCALAPPDATA"), "Programs", "Android Studio"),
+		} {
+			locs = append(locs,
+				Location{filepath.Join(studio, "jbr"), "Android Studio"},
+				Location{filepath.Join(studio, "jre"), "Android Studio"},
+			)
+		}
+		for _, vendor := range []string{"Eclipse Adoptium", "Java", "Zulu", "Microsoft", "Amazon Corretto", "BellSoft"} {
+			locs = append(locs, Location{filepath.Join(programFiles, vendor, "*"), vendor})
+		}
+
+	case "darwin":
+		for _, apps := range []string{"/Applications", filepath.Join(home, "Applications")} {
+			studio := filepath.Join(apps, "Android Studio.app", "Contents")
+			locs = append(locs,
+				Location{filepath.Join(studio, "jbr", "Contents", "Home"), "Android Studio"},
+				Location{filepath.Join(studio, "jre", "Contents", "Home"), "Android Studio"},
+			)
+		}
+		locs = append(locs,
+			Location{"/Library/Java/JavaVirtualMachines/*/Contents/Home", "/Library/Java"},
+			Location{filepath.Join(home, "Library", "Java", "JavaVirtualMachines", "*", "Contents", "Home"), "~/Library/Java"},
+			Location{"/opt/homebrew/opt/openjdk*/libexec/openjdk.jdk/Contents/Home", "Homebrew"},
+			Location{"/usr/local/opt/openjdk*/libexec/openjdk.jdk/Contents/Home", "Homebrew"},
+		)
+
+	default:
+		for _, studio := range []string{
+			"/opt/android-studio",
+			"/usr/local/android-studio",
+			filepath.Join(home, "android-studio"),
+			"/snap/android-studio/current",
+		} {
+			locs = append(locs, Location{filepath.Join(studio, "jbr"), "Android Studio"})
+		}
+		locs = append(locs, Location{"/usr/lib/jvm/*", "System"})
+	}
+
+	if goos != "windows" {
+		locs = append(locs, Location{filepath.Join(home, ".sdkman", "candidates", "java", "*"), "SDKMAN"})
+	}
+	return append(locs,
+		Location{filepath.Join(home, ".jdks", "*"), "IntelliJ"},
+		Location{filepath.Join(home, ".gradle", "jdks", "*"), "Gradle"},
+	)
+}
+
+// HomeOf returns the JDK home of a java executable, following symlinks
+// such as /usr/bin/java -> /etc/alternatives/java
+func HomeOf(java string) string {
+	if resolved, err := filepath.EvalSymlinks(java); err == nil {
+		java = resolved
+	}
+	return filepath.Dir(filepath.Dir(java))
+}
+
+// Discover finds the JDKs at locations and reads their versions. Homes
+// without bin/java are skipped, as are homes already found through a
+// symlink; the first location a JDK is found at wins
+func Discover(ctx context.Context, r runner.CommandRunner, goos string, locations []Location) []Install {
+	exe := func(name string) string {
+		if goos == "windows" {
+			return name + ".exe"
+		}
+		return name
+	}
+
+	var installs []Install
+	seen := make(map[string]bool)
+	for _, loc := range locations {
+		homes, _ := filepath.Glob(loc.Pattern)
+		sort.Strings(homes)
+		for _, home := range homes {
+			key := home
+			if resolved, err := filepath.EvalSymlinks(home); err == nil {
+				key = resolved
+			}
+			if seen[key] || !isFile(filepath.Join(home, "bin", exe("java"))) {
+				continue
+			}
+			seen[key] = true
+			installs = append(installs, Install{
+				Home:     home,
+				Source:   loc.Source,
+				HasJavac: isFile(filepath.Join(home, "bin", exe("javac"))),
+			})
+		}
+	}
+
+	// Read the versions concurrently; a JDK whose java does not run is dropped
+	ok := make([]bool, len(installs))
+	var wg sync.WaitGroup
+	for i := range installs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+			defer cancel()
+
+			java := filepath.Join(installs[i].Home, "bin", exe("java"))
+			result, err := r.Run(ctx, java, "-version")
+			if err != nil {
+				return
+			}
+			if info, err := Parse(result.Combined()); err == nil {
+				installs[i].Info = info
+				ok[i] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	found := installs[:0]
+	for i, install := range installs {
+		if ok[i] {
+			found = append(found, install)
+		}
+	}
+	return found
+}
+
+// Recommend picks the install Flutter should use: a usable JDK with the
+// lowest feature release, since Java 17 works with every Gradle version
+// Flutter projects use while newer releases need a newer Gradle. Ties go
+// to Android Studio's JBR, which flutter uses by default, then to the
+// latest update
+func Recommend(installs []Install) (Install, bool) {
+	best, found := Install{}, false
+	for _, install := range installs {
+		if !install.Usable() {
+			continue
+		}
+		if !found || better(install, best) {
+			best, found = install, true
+		}
+	}
+	return best, found
+}
+
+func better(a, b Install) bool {
+	if a.Info.Version.Major != b.Info.Version.Major {
+		return a.Info.Version.Major < b.Info.Version.Major
+	}
+	if studioA, studioB := a.Source == "Android Studio", b.Source == "Android Studio"; studioA != studioB {
+		return studioA
+	}
+	return b.Info.Version.Less(a.Info.Version)
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,166 @@
+package jdk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"flutter_takeoff/pkg/runner"
+)
+
+// makeJDK creates a home with bin/java, and bin/javac when jdk is set
+func makeJDK(t *testing.T, home string, jdk bool) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(home, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{"java"}
+	if jdk {
+		files = append(files, "javac")
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(home, "bin", name), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func javaVersion(version, runtime string) runner.Result {
+	return runner.Result{Stderr: "openjdk version \"" + version + "\" 2024-04-16\nOpenJDK Runtime Environment " + runtime + "\n"}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	jvm := filepath.Join(root, "usr", "lib", "jvm")
+	studio := filepath.Join(root, "opt", "android-studio", "jbr")
+	sdkman := filepath.Join(root, "home", ".sdkman", "candidates", "java")
+
+	makeJDK(t, filepath.Join(jvm, "java-11-openjdk-amd64"), true)
+	makeJDK(t, filepath.Join(jvm, "java-17-openjdk-amd64"), false) // headless JRE
+	makeJDK(t, studio, true)
+	makeJDK(t, filepath.Join(sdkman, "17.0.11-tem"), true)
+	makeJDK(t, filepath.Join(sdkman, "21.0.3-zulu"), true)
+	makeJDK(t, filepath.Join(root, "broken"), true)
+	if err := os.MkdirAll(filepath.Join(jvm, "not-a-jdk"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// SDKMAN's current is a symlink to one of the installs
+	if err := os.Symlink(filepath.Join(sdkman, "17.0.11-tem"), filepath.Join(sdkman, "current")); err != nil {
+		t.Fatal(err)
+	}
+
+	java := func(home string) string { return filepath.Join(home, "bin", "java") + " -version" }
+	fake := runner.NewFake().
+		On(java(filepath.Join(jvm, "java-11-openjdk-amd64")), javaVersion("11.0.22", "(build 11.0.22+7-post-Ubuntu)")).
+		On(java(filepath.Join(jvm, "java-17-openjdk-amd64")), javaVersion("17.0.10", "(build 17.0.10+7-Ubuntu)")).
+		On(java(studio), javaVersion("17.0.9", "JBR-17.0.9+7-1087.11-jcef (build 17.0.9+7-b1087.11)")).
+		On(java(filepath.Join(sdkman, "current")), javaVersion("17.0.11", "Temurin-17.0.11+9 (build 17.0.11+9)")).
+		On(java(filepath.Join(sdkman, "21.0.3-zulu")), javaVersion("21.0.3", "Zulu21.34+19-CA (build 21.0.3+9-LTS)"))
+
+	installs := Discover(context.Background(), fake, "linux", []Location{
+		{filepath.Join(sdkman, "current"), "JAVA_HOME"},
+		{studio, "Android Studio"},
+		{filepath.Join(jvm, "*"), "System"},
+		{filepath.Join(sdkman, "*"), "SDKMAN"},
+		{filepath.Join(root, "broken"), "Other"},
+		{filepath.Join(root, "missing", "*"), "Other"},
+	})
+
+	want := []struct {
+		home     string
+		source   string
+		version  string
+		vendor   string
+		hasJavac bool
+	}{
+		{filepath.Join(sdkman, "current"), "JAVA_HOME", "17.0.11", "Eclipse Temurin", true},
+		{studio, "Android Studio", "17.0.9", "JetBrains Runtime", true},
+		{filepath.Join(jvm, "java-11-openjdk-amd64"), "System", "11.0.22", "OpenJDK", true},
+		{filepath.Join(jvm, "java-17-openjdk-amd64"), "System", "17.0.10", "OpenJDK", false},
+		{filepath.Join(sdkman, "21.0.3-zulu"), "SDKMAN", "21.0.3", "Azul Zulu", true},
+	}
+	if len(installs) != len(want) {
+		t.Fatalf("found %d installs, want %d: %+v", len(installs), len(want), installs)
+	}
+	for i, w := range want {
+		got := installs[i]
+		if got.Home != w.home || got.Source != w.source || got.Info.Version.String() != w.version ||
+			got.Info.Vendor != w.vendor || got.HasJavac != w.hasJavac {
+			t.Errorf("install %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	best, ok := Recommend(installs)
+	if !ok || best.Home != studio {
+		t.Errorf("Recommend = %s, want Android Studio's JBR 17 at %s", best.Home, studio)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	install := func(source string, major, patch int, javac bool) Install {
+		return Install{Source: source, Info: Info{Version: Version{Major: major, Patch: patch}}, HasJavac: javac}
+	}
+
+	tests := []struct {
+		name     string
+		installs []Install
+		want     int
+	}{
+		{"only too old or JRE", []Install{install("System", 11, 0, true), install("System", 17, 0, false)}, -1},
+		{"lowest supported release", []Install{install("SDKMAN", 21, 3, true), install("System", 17, 1, true)}, 1},
+		{"android studio breaks ties", []Install{install("System", 17, 11, true), install("Android Studio", 17, 9, true)}, 1},
+		{"latest update", []Install{install("System", 17, 2, true), install("SDKMAN", 17, 11, true)}, 1},
+	}
+	for _, tt := range tests {
+		got, ok := Recommend(tt.installs)
+		if tt.want < 0 {
+			if ok {
+				t.Errorf("%s: Recommend = %+v, want none", tt.name, got)
+			}
+			continue
+		}
+		if !ok || got != tt.installs[tt.want] {
+			t.Errorf("%s: Recommend = %+v, want %+v", tt.name, got, tt.installs[tt.want])
+		}
+	}
+}
+
+func TestLocations(t *testing.T) {
+	getenv := func(env map[string]string) func(string) string {
+		return func(key string) string { return env[key] }
+	}
+
+	win := Locations("windows", `C:\Users\dev`, getenv(map[string]string{
+		"JAVA_HOME":    `C:\jdk`,
+		"ProgramFiles": `D:\Apps`,
+	}))
+	if win[0] != (Location{`C:\jdk`, "JAVA_HOME"}) {
+		t.Errorf("first windows location = %+v, want JAVA_HOME", win[0])
+	}
+	if !hasLocation(win, filepath.Join(`D:\Apps`, "Android", "Android Studio", "jbr"), "Android Studio") ||
+		!hasLocation(win, filepath.Join(`D:\Apps`, "Eclipse Adoptium", "*"), "Eclipse Adoptium") {
+		t.Errorf("windows locations miss Program Files: %+v", win)
+	}
+
+	mac := Locations("darwin", "/Users/dev", getenv(nil))
+	if !hasLocation(mac, "/Applications/Android Studio.app/Contents/jbr/Contents/Home", "Android Studio") ||
+		!hasLocation(mac, "/Library/Java/JavaVirtualMachines/*/Contents/Home", "/Library/Java") ||
+		!hasLocation(mac, "/Users/dev/.sdkman/candidates/java/*", "SDKMAN") {
+		t.Errorf("macOS locations = %+v", mac)
+	}
+
+	linux := Locations("linux", "/home/dev", getenv(nil))
+	if !hasLocation(linux, "/usr/lib/jvm/*", "System") || !hasLocation(linux, "/home/dev/android-studio/jbr", "Android Studio") {
+		t.Errorf("linux locations = %+v", linux)
+	}
+}
+
+func hasLocation(locs []Location, pattern, source string) bool {
+	for _, loc := range locs {
+		if loc.Pattern == pattern && loc.Source == source {
+			return true
+		}
+	}
+	return false
+}
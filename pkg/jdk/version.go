@@ -0,0 +1,154 @@
+package jdk
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MinimumMajor is the oldest Java release the Android Gradle plugin used
+// by Flutter accepts
+const MinimumMajor = 17
+
+// Version is a Java feature release with its update numbers
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is older than o
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// Info describes a Java runtime from its `java -version` output
+type Info struct {
+	Vendor  string
+	Version Version
+}
+
+func (i Info) String() string {
+	return i.Version.String() + " (" + i.Vendor + ")"
+}
+
+// Supported reports whether the runtime is new enough for Flutter
+func (i Info) Supported() bool {
+	return i.Version.Major >= MinimumMajor
+}
+
+var (
+	// openjdk version "17.0.11" 2024-04-16, java version "1.8.0_401"
+	versionLine = regexp.MustCompile(`(?m)^\S+ version "([^"]+)"`)
+	// javac 17.0.11
+	javacLine = regexp.MustCompile(`(?m)^javac (\S+)`)
+	// Android Studio's JBR builds are tagged like b1087.21 or b509.11
+	jbrBuild = regexp.MustCompile(`\bb\d{3,4}\.\d+\b`)
+)
+
+// vendors maps text found in `java -version` output to a vendor name,
+// most specific first
+var vendors = []struct{ marker, name string }{
+	{"Temurin", "Eclipse Temurin"},
+	{"AdoptOpenJDK", "AdoptOpenJDK"},
+	{"Zulu", "Azul Zulu"},
+	{"JBR", "JetBrains Runtime"},
+	{"Corretto", "Amazon Corretto"},
+	{"Microsoft", "Microsoft Build of OpenJDK"},
+	{"GraalVM", "GraalVM"},
+	{"Homebrew", "Homebrew OpenJDK"},
+	{"Java(TM)", "Oracle"},
+}
+
+// Parse reads the vendor and version from the output of `java -version`,
+// which most runtimes print to stderr. Lines printed before the version,
+// such as "Picked up JAVA_TOOL_OPTIONS", are ignored
+func Parse(output string) (Info, error) {
+	m := versionLine.FindStringSubmatch(output)
+	if m == nil {
+		return Info{}, fmt.Errorf("no Java version in %q", firstLine(output))
+	}
+	v, err := ParseVersion(m[1])
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{Vendor: vendor(output), Version: v}, nil
+}
+
+// ParseJavac reads the version from the output of `javac -version`
+func ParseJavac(output string) (Version, error) {
+	m := javacLine.FindStringSubmatch(output)
+	if m == nil {
+		return Version{}, fmt.Errorf("no javac version in %q", firstLine(output))
+	}
+	return ParseVersion(m[1])
+}
+
+// ParseVersion parses a Java version string: "17.0.11", "21", "21-ea",
+// "11.0.22+7" or the legacy "1.8.0_401" form, which is Java 8 update 401
+func ParseVersion(s string) (Version, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+
+	var patch string
+	if strings.HasPrefix(s, "1.") {
+		// 1.8.0_401: the feature release is the second number
+		s, patch, _ = strings.Cut(s[2:], "_")
+		if i := strings.LastIndex(s, "."); i >= 0 {
+			s = s[:i]
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	nums := make([]int, 3)
+	for i := 0; i < len(parts) && i < 3; i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid Java version %q", s)
+		}
+		nums[i] = n
+	}
+	if patch != "" {
+		n, err := strconv.Atoi(patch)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid Java update %q", patch)
+		}
+		nums[2] = n
+	}
+
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+func vendor(output string) string {
+	for _, v := range vendors {
+		if strings.Contains(output, v.marker) {
+			return v.name
+		}
+	}
+	if jbrBuild.MatchString(output) {
+		return "JetBrains Runtime"
+	}
+	if strings.HasPrefix(strings.TrimSpace(versionLine.FindString(output)), "java version") {
+		// Only Oracle's builds print "java version" without Java(TM)
+		return "Oracle"
+	}
+	return "OpenJDK"
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
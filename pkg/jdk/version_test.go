@@ -0,0 +1,128 @@
+package jdk
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   Info
+	}{
+		{
+			name: "temurin 17",
+			output: `openjdk version "17.0.11" 2024-04-16
+OpenJDK Runtime Environment Temurin-17.0.11+9 (build 17.0.11+9)
+OpenJDK 64-Bit Server VM Temurin-17.0.11+9 (build 17.0.11+9, mixed mode, sharing)
+`,
+			want: Info{Vendor: "Eclipse Temurin", Version: Version{17, 0, 11}},
+		},
+		{
+			name: "zulu 21",
+			output: `openjdk version "21.0.3" 2024-04-16 LTS
+OpenJDK Runtime Environment Zulu21.34+19-CA (build 21.0.3+9-LTS)
+OpenJDK 64-Bit Server VM Zulu21.34+19-CA (build 21.0.3+9-LTS, mixed mode, sharing)
+`,
+			want: Info{Vendor: "Azul Zulu", Version: Version{21, 0, 3}},
+		},
+		{
+			name: "oracle 17",
+			output: `java version "17.0.2" 2022-01-18 LTS
+Java(TM) SE Runtime Environment (build 17.0.2+8-LTS-86)
+Java HotSpot(TM) 64-Bit Server VM (build 17.0.2+8-LTS-86, mixed mode, sharing)
+`,
+			want: Info{Vendor: "Oracle", Version: Version{17, 0, 2}},
+		},
+		{
+			name: "oracle 8",
+			output: `java version "1.8.0_401"
+Java(TM) SE Runtime Environment (build 1.8.0_401-b10)
+Java HotSpot(TM) 64-Bit Server VM (build 25.401-b10, mixed mode)
+`,
+			want: Info{Vendor: "Oracle", Version: Version{8, 0, 401}},
+		},
+		{
+			name: "android studio jbr tagged",
+			output: `openjdk version "17.0.9" 2023-10-17
+OpenJDK Runtime Environment JBR-17.0.9+7-1087.11-jcef (build 17.0.9+7-b1087.11)
+OpenJDK 64-Bit Server VM JBR-17.0.9+7-1087.11-jcef (build 17.0.9+7-b1087.11, mixed mode)
+`,
+			want: Info{Vendor: "JetBrains Runtime", Version: Version{17, 0, 9}},
+		},
+		{
+			name: "android studio jbr untagged",
+			output: `openjdk version "21.0.3" 2024-04-16
+OpenJDK Runtime Environment (build 21.0.3+-12282718-b509.11)
+OpenJDK 64-Bit Server VM (build 21.0.3+-12282718-b509.11, mixed mode)
+`,
+			want: Info{Vendor: "JetBrains Runtime", Version: Version{21, 0, 3}},
+		},
+		{
+			name: "distribution openjdk 11",
+			output: `openjdk version "11.0.22" 2024-01-16
+OpenJDK Runtime Environment (build 11.0.22+7-post-Ubuntu-0ubuntu222.04.1)
+OpenJDK 64-Bit Server VM (build 11.0.22+7-post-Ubuntu-0ubuntu222.04.1, mixed mode, sharing)
+`,
+			want: Info{Vendor: "OpenJDK", Version: Version{11, 0, 22}},
+		},
+		{
+			name: "feature release without update",
+			output: `Picked up JAVA_TOOL_OPTIONS: -Dfile.encoding=UTF-8
+openjdk version "21" 2023-09-19
+OpenJDK Runtime Environment (build 21+35-2513)
+OpenJDK 64-Bit Server VM (build 21+35-2513, mixed mode, sharing)
+`,
+			want: Info{Vendor: "OpenJDK", Version: Version{21, 0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, output := range []string{
+		"",
+		"The operation couldn’t be completed. Unable to locate a Java Runtime.",
+		`openjdk version "seventeen"`,
+	} {
+		if info, err := Parse(output); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", output, info)
+		}
+	}
+}
+
+func TestParseJavac(t *testing.T) {
+	tests := []struct {
+		output string
+		want   Version
+	}{
+		{"javac 17.0.11\n", Version{17, 0, 11}},
+		{"javac 1.8.0_401\n", Version{8, 0, 401}},
+		{"Picked up JAVA_TOOL_OPTIONS: -Xmx1g\njavac 21\n", Version{21, 0, 0}},
+	}
+	for _, tt := range tests {
+		got, err := ParseJavac(tt.output)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseJavac(%q) = %v, %v; want %v", tt.output, got, err, tt.want)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for major, want := range map[int]bool{8: false, 11: false, 16: false, 17: true, 21: true} {
+		if got := (Info{Version: Version{Major: major}}).Supported(); got != want {
+			t.Errorf("Java %d supported = %v, want %v", major, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"flutter_takeoff/pkg/installer"
+	"flutter_takeoff/pkg/releases"
+)
+
+// FileName is the name of the project config file, kept in the project
+// root next to pubspec.yaml
+const FileName = ".flutter-takeoff.json"
+
+// Targets are the platforms a project can build for
+var Targets = []installer.TargetPlatform{
+	installer.TargetAndroid,
+	installer.TargetIOS,
+	installer.TargetWeb,
+	installer.TargetDesktop,
+}
+
+// validRevision matches build-tools versions such as "34.0.0"
+var validRevision = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// Config pins the toolchain a project builds with:
+//
+//	{
+//	  "flutter_version": "3.24.5",
+//	  "channel": "stable",
+//	  "min_jdk": 17,
+//	  "android_build_tools": "34.0.0",
+//	  "targets": ["android", "ios"]
+//	}
+type Config struct {
+	// FlutterVersion is an exact version, a release line such as
+	// "3.22.x" or "latest"
+	FlutterVersion string `json:"flutter_version"`
+	// Channel the version is released on. Defaults to stable
+	Channel string `json:"channel,omitempty"`
+	// MinJDK is the oldest Java feature release the project builds with.
+	// Defaults to jdk.MinimumMajor
+	MinJDK int `json:"min_jdk,omitempty"`
+	// AndroidBuildTools is the oldest build-tools version the project
+	// needs, when it is newer than what Flutter needs
+	AndroidBuildTools string `json:"android_build_tools,omitempty"`
+	// Targets lists the platforms the project builds for. Defaults to
+	// android
+	Targets []installer.TargetPlatform `json:"targets,omitempty"`
+}
+
+// Find looks for the config file in dir and then in each parent, so it
+// is found from anywhere inside the project, and returns its path
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s found in %s or its parents", FileName, dir)
+		}
+		dir = parent
+	}
+}
+
+// Load reads and validates a config file. Unknown fields are rejected,
+// so a misspelt setting is not silently ignored
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+func (c *Config) validate() error {
+	if c.FlutterVersion == "" {
+		return errors.New("flutter_version is required")
+	}
+	if c.Channel != "" && !slices.Contains(releases.Channels, c.Channel) {
+		return fmt.Errorf("unknown channel %q", c.Channel)
+	}
+	if c.MinJDK < 0 {
+		return fmt.Errorf("min_jdk %d is not a Java release", c.MinJDK)
+	}
+	if c.AndroidBuildTools != "" && !validRevision.MatchString(c.AndroidBuildTools) {
+		return fmt.Errorf("android_build_tools %q is not a build-tools version", c.AndroidBuildTools)
+	}
+	for _, t := range c.Targets {
+		if !slices.Contains(Targets, t) {
+			return fmt.Errorf("unknown target %q: use %v", t, Targets)
+		}
+	}
+	return nil
+}
+
+// Builds reports whether the project builds for target. A config
+// without targets builds for Android
+func (c *Config) Builds(target installer.TargetPlatform) bool {
+	if len(c.Targets) == 0 {
+		return target == installer.TargetAndroid
+	}
+	return slices.Contains(c.Targets, target)
+}
@@ -0,0 +1,102 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"flutter_takeoff/pkg/installer"
+)
+
+// writeConfig writes a config file into dir and returns its path
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	want := writeConfig(t, root, `{"flutter_version": "3.24.5"}`)
+	nested := filepath.Join(root, "lib", "src")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{root, nested} {
+		if got, err := Find(dir); err != nil || got != want {
+			t.Errorf("Find(%s) = %q, %v; want %q", dir, got, err, want)
+		}
+	}
+
+	if _, err := Find(t.TempDir()); err == nil || !strings.Contains(err.Error(), FileName) {
+		t.Errorf("Find() without a config error = %v, want it to name %s", err, FileName)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), `{
+  "flutter_version": "3.24.x",
+  "channel": "stable",
+  "min_jdk": 21,
+  "android_build_tools": "35.0.0",
+  "targets": ["android", "ios"]
+}`)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := &Config{
+		FlutterVersion:    "3.24.x",
+		Channel:           "stable",
+		MinJDK:            21,
+		AndroidBuildTools: "35.0.0",
+		Targets:           []installer.TargetPlatform{installer.TargetAndroid, installer.TargetIOS},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Load() = %+v, want %+v", c, want)
+	}
+	if !c.Builds(installer.TargetIOS) || c.Builds(installer.TargetWeb) {
+		t.Errorf("Builds() does not follow targets %v", c.Targets)
+	}
+}
+
+func TestLoadDefaultsToAndroid(t *testing.T) {
+	c, err := Load(writeConfig(t, t.TempDir(), `{"flutter_version": "latest"}`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !c.Builds(installer.TargetAndroid) || c.Builds(installer.TargetIOS) {
+		t.Error("a config without targets should build for Android only")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`{"channel": "stable"}`, "flutter_version is required"},
+		{`{"flutter_version": "3.24.5", "channel": "nightly"}`, `unknown channel "nightly"`},
+		{`{"flutter_version": "3.24.5", "channel": "dev"}`, `unknown channel "dev"`},
+		{`{"flutter_version": "3.24.5", "min_jdk": -1}`, "min_jdk"},
+		{`{"flutter_version": "3.24.5", "android_build_tools": "latest"}`, "android_build_tools"},
+		{`{"flutter_version": "3.24.5", "targets": ["fuchsia"]}`, `unknown target "fuchsia"`},
+		// A misspelt setting is an error rather than silently ignored
+		{`{"flutter_version": "3.24.5", "minJdk": 21}`, "minJdk"},
+		{`{"flutter_version": 3.24}`, "flutter_version"},
+	}
+	for _, tt := range tests {
+		_, err := Load(writeConfig(t, t.TempDir(), tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Load(%s) error = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+}
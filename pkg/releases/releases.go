@@ -0,0 +1,199 @@
+package releases
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Channels in the order they are offered to users. The dev channel was
+// retired and has no current release
+var Channels = []string{"stable", "beta"}
+
+// Release is one entry of the releases manifest
+type Release struct {
+	Hash           string    `json:"hash"`
+	Channel        string    `json:"channel"`
+	Version        string    `json:"version"`
+	DartSDKVersion string    `json:"dart_sdk_version"`
+	DartSDKArch    string    `json:"dart_sdk_arch"`
+	ReleaseDate    time.Time `json:"release_date"`
+	Archive        string    `json:"archive"`
+	SHA256         string    `json:"sha256"`
+}
+
+// Arch returns the architecture of the release archive. Manifests only
+// started listing it once arm64 builds appeared, so missing means x64
+func (r Release) Arch() string {
+	if r.DartSDKArch == "" {
+		return "x64"
+	}
+	return r.DartSDKArch
+}
+
+// Manifest is the parsed releases_<os>.json file
+type Manifest struct {
+	BaseURL        string            `json:"base_url"`
+	CurrentRelease map[string]string `json:"current_release"`
+	Releases       []Release         `json:"releases"`
+}
+
+// ManifestName returns the manifest file name for an operating system
+// ("windows", "macos" or "linux")
+func ManifestName(osName string) string {
+	return "releases_" + osName + ".json"
+}
+
+// Fetch downloads and parses the manifest at url
+func Fetch(client *http.Client, url string) (*Manifest, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch releases manifest %s: %s", url, resp.Status)
+	}
+
+	return Parse(resp.Body)
+}
+
+// Parse decodes a releases manifest
+func Parse(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to parse releases manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Resolve finds the release matching query on channel for arch:
+//
+//   - "" or "latest" is the current release of the channel
+//   - "3.22.x" is the newest release of the 3.22 line
+//   - anything else must match a version exactly
+//
+// An empty channel matches every channel, except for "latest" which
+// then means stable. An empty arch matches every architecture.
+func (m *Manifest) Resolve(query, channel, arch string) (*Release, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "v")
+
+	if query == "" || query == "latest" {
+		if channel == "" {
+			channel = "stable"
+		}
+		hash, ok := m.CurrentRelease[channel]
+		if !ok {
+			return nil, fmt.Errorf("unknown channel %q", channel)
+		}
+		for i, r := range m.Releases {
+			if r.Hash == hash && r.Channel == channel && matchesArch(r, arch) {
+				return &m.Releases[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no %s archive for the current %s release", archName(arch), channel)
+	}
+
+	var best *Release
+	prefix, wildcard := strings.CutSuffix(query, ".x")
+	for i, r := range m.Releases {
+		if channel != "" && r.Channel != channel || !matchesArch(r, arch) {
+			continue
+		}
+		if wildcard {
+			if !strings.HasPrefix(r.Version, prefix+".") {
+				continue
+			}
+		} else if r.Version != query {
+			continue
+		}
+		if best == nil || CompareVersions(r.Version, best.Version) > 0 {
+			best = &m.Releases[i]
+		}
+	}
+
+	if best == nil {
+		if channel != "" {
+			return nil, fmt.Errorf("no %s release matches %q on the %s channel", archName(arch), query, channel)
+		}
+		return nil, fmt.Errorf("no %s release matches %q", archName(arch), query)
+	}
+	return best, nil
+}
+
+// Filter returns the releases on channel for arch, newest first as in the manifest
+func (m *Manifest) Filter(channel, arch string) []Release {
+	var out []Release
+	for _, r := range m.Releases {
+		if (channel == "" || r.Channel == channel) && matchesArch(r, arch) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func matchesArch(r Release, arch string) bool {
+	return arch == "" || r.Arch() == arch
+}
+
+func archName(arch string) string {
+	if arch == "" {
+		return "Flutter"
+	}
+	return arch
+}
+
+// CompareVersions compares two Flutter versions such as "3.22.1" and
+// "3.23.0-0.1.pre". Returns -1 if a < b, 0 if equal, 1 if a > b. A
+// pre-release sorts before the release it precedes
+func CompareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	if c := compareDotted(aCore, bCore); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareDotted(aPre, bPre)
+}
+
+// compareDotted compares dot-separated fields numerically where possible
+func compareDotted(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y string
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				return cmp.Compare(xn, yn)
+			}
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
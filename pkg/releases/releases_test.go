@@ -0,0 +1,124 @@
+package releases
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func loadManifest(t *testing.T) *Manifest {
+	t.Helper()
+	f, err := os.Open("testdata/releases_macos.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	m, err := Parse(f)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return m
+}
+
+func TestParse(t *testing.T) {
+	m := loadManifest(t)
+
+	if m.BaseURL != "https://storage.googleapis.com/flutter_infra_release/releases" {
+		t.Errorf("BaseURL = %q", m.BaseURL)
+	}
+	if got := m.CurrentRelease["stable"]; got != "dec2ee5c1f98f8e84a7d5380c05eb8a3d0a81668" {
+		t.Errorf("current stable = %q", got)
+	}
+	if len(m.Releases) != 9 {
+		t.Fatalf("got %d releases, want 9", len(m.Releases))
+	}
+
+	r := m.Releases[2]
+	if r.Version != "3.24.5" || r.DartSDKVersion != "3.5.4" || r.Channel != "stable" ||
+		r.Archive != "stable/macos/flutter_macos_arm64_3.24.5-stable.zip" || r.SHA256 == "" {
+		t.Errorf("unexpected release %+v", r)
+	}
+	if r.ReleaseDate.Year() != 2024 {
+		t.Errorf("ReleaseDate = %v", r.ReleaseDate)
+	}
+	if got := m.Releases[8].Arch(); got != "x64" {
+		t.Errorf("Arch() without dart_sdk_arch = %q, want x64", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	m := loadManifest(t)
+
+	tests := []struct {
+		query, channel, arch string
+		want                 string
+		wantErr              bool
+	}{
+		{query: "latest", channel: "stable", arch: "arm64", want: "stable/macos/flutter_macos_arm64_3.24.5-stable.zip"},
+		{query: "", channel: "", arch: "x64", want: "stable/macos/flutter_macos_3.24.5-stable.zip"},
+		{query: "latest", channel: "beta", arch: "arm64", want: "beta/macos/flutter_macos_arm64_3.27.0-0.2.pre-beta.zip"},
+		{query: "latest", channel: "master", wantErr: true},
+		{query: "3.22.x", channel: "stable", arch: "arm64", want: "stable/macos/flutter_macos_arm64_3.22.10-stable.zip"},
+		{query: "3.24.0", channel: "stable", arch: "arm64", want: "stable/macos/flutter_macos_arm64_3.24.0-stable.zip"},
+		{query: "v3.24.0", channel: "", arch: "arm64", want: "stable/macos/flutter_macos_arm64_3.24.0-stable.zip"},
+		{query: "3.24.0", channel: "stable", arch: "x64", wantErr: true},
+		{query: "3.0.0", channel: "stable", arch: "x64", want: "stable/macos/flutter_macos_3.0.0-stable.zip"},
+		{query: "3.27.x", channel: "", arch: "x64", want: "beta/macos/flutter_macos_3.27.0-0.2.pre-beta.zip"},
+		{query: "3.27.x", channel: "stable", arch: "arm64", wantErr: true},
+		{query: "2.10.5", channel: "stable", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := m.Resolve(tt.query, tt.channel, tt.arch)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Resolve(%q, %q, %q) = %s, want error", tt.query, tt.channel, tt.arch, got.Archive)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%q, %q, %q) error = %v", tt.query, tt.channel, tt.arch, err)
+			continue
+		}
+		if got.Archive != tt.want {
+			t.Errorf("Resolve(%q, %q, %q) = %s, want %s", tt.query, tt.channel, tt.arch, got.Archive, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"3.22.10", "3.22.3", 1},
+		{"3.22.3", "3.22.3", 0},
+		{"3.24.0", "3.24.0-0.1.pre", 1},
+		{"3.24.0-0.2.pre", "3.24.0-0.1.pre", 1},
+		{"3.9.0", "3.10.0", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	m, err := Fetch(server.Client(), server.URL+"/"+ManifestName("macos"))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(m.Releases) == 0 {
+		t.Error("Fetch() returned no releases")
+	}
+
+	if _, err := Fetch(server.Client(), server.URL+"/"+ManifestName("linux")); err == nil {
+		t.Error("Fetch() of a missing manifest should fail")
+	}
+}
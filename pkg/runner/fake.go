@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fake is a scripted CommandRunner for tests. Commands are matched by
+// their full command line, e.g. "git --version", and answered with a
+// canned Result; anything not scripted fails as if the executable were
+// not installed. It is safe for concurrent use
+type Fake struct {
+	// Commands maps a command line to its canned result. A non-zero
+	// ExitCode makes Run return an error, like a failing process
+	Commands map[string]Result
+	// Paths maps an executable name to what LookPath returns
+	Paths map[string]string
+	// Delays maps a command line to how long it runs before answering.
+	// A command still running when its context is done fails with the
+	// context's error, like a killed process
+	Delays map[string]time.Duration
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var _ CommandRunner = (*Fake)(nil)
+
+// NewFake creates a Fake with no scripted commands
+func NewFake() *Fake {
+	return &Fake{Commands: map[string]Result{}, Paths: map[string]string{}, Delays: map[string]time.Duration{}}
+}
+
+// On scripts the result of a command line and returns f for chaining
+func (f *Fake) On(cmdline string, result Result) *Fake {
+	if f.Commands == nil {
+		f.Commands = map[string]Result{}
+	}
+	f.Commands[cmdline] = result
+	return f
+}
+
+// OnPath scripts where LookPath finds file and returns f for chaining
+func (f *Fake) OnPath(file, path string) *Fake {
+	if f.Paths == nil {
+		f.Paths = map[string]string{}
+	}
+	f.Paths[file] = path
+	return f
+}
+
+// Delay makes a command line take d to run and returns f for chaining
+func (f *Fake) Delay(cmdline string, d time.Duration) *Fake {
+	if f.Delays == nil {
+		f.Delays = map[string]time.Duration{}
+	}
+	f.Delays[cmdline] = d
+	return f
+}
+
+// Run returns the scripted result for the command line
+func (f *Fake) Run(ctx context.Context, name string, args ...string) (Result, error) {
+	line := CommandLine(name, args...)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, line)
+	result, ok := f.Commands[line]
+	delay := f.Delays[line]
+	f.mu.Unlock()
+
+	if !ok {
+		return Result{ExitCode: -1}, &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return Result{ExitCode: -1}, fmt.Errorf("%s: %w", line, ctx.Err())
+	case <-timer.C:
+	}
+
+	if result.ExitCode != 0 {
+		return result, fmt.Errorf("%s: exit status %d", line, result.ExitCode)
+	}
+	return result, nil
+}
+
+// RunAttached behaves like Run; the scripted output is discarded
+func (f *Fake) RunAttached(ctx context.Context, name string, args ...string) error {
+	_, err := f.Run(ctx, name, args...)
+	return err
+}
+
+// LookPath returns the scripted path for file
+func (f *Fake) LookPath(file string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if path, ok := f.Paths[file]; ok {
+		return path, nil
+	}
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
+
+// Calls returns the command lines run so far, in order
+func (f *Fake) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// CommandLine joins a command and its arguments with spaces, the form
+// Fake matches commands by
+func CommandLine(name string, args ...string) string {
+	return strings.Join(append([]string{name}, args...), " ")
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake().
+		On("git --version", Result{Stdout: "git version 2.45.2\n"}).
+		On("java -version", Result{Stderr: "Unable to locate a Java Runtime.\n", ExitCode: 1}).
+		OnPath("git", "/usr/bin/git")
+
+	result, err := f.Run(ctx, "git", "--version")
+	if err != nil || result.Stdout != "git version 2.45.2\n" {
+		t.Errorf("git --version = %+v, %v", result, err)
+	}
+
+	result, err = f.Run(ctx, "java", "-version")
+	if err == nil || result.ExitCode != 1 || result.Combined() != "Unable to locate a Java Runtime.\n" {
+		t.Errorf("java -version = %+v, %v; want the canned failure", result, err)
+	}
+
+	if _, err := f.Run(ctx, "adb", "version"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("unscripted command error = %v, want exec.ErrNotFound", err)
+	}
+	if err := f.RunAttached(ctx, "flutter", "doctor", "--android-licenses"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("unscripted attached command error = %v, want exec.ErrNotFound", err)
+	}
+
+	if path, err := f.LookPath("git"); err != nil || path != "/usr/bin/git" {
+		t.Errorf("LookPath(git) = %q, %v", path, err)
+	}
+	if _, err := f.LookPath("java"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("LookPath(java) error = %v, want exec.ErrNotFound", err)
+	}
+
+	want := []string{"git --version", "java -version", "adb version", "flutter doctor --android-licenses"}
+	if got := f.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %q, want %q", got, want)
+	}
+}
+
+func TestFakeDelay(t *testing.T) {
+	f := NewFake().
+		On("flutter --version", Result{Stdout: "Flutter 3.24.5\n"}).
+		Delay("flutter --version", time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := f.Run(ctx, "flutter", "--version"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("slow command error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessTree starts cmd in its own process group and makes
+// cancelling it kill the whole group. flutter is a shell script that
+// starts dart, which would otherwise be left running
+func killProcessTree(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
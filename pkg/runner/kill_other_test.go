@@ -0,0 +1,34 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecRunKillsProcessTree(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The background sleep keeps stdout open; if only sh were killed,
+	// Run would wait for it until waitDelay runs out
+	start := time.Now()
+	_, err := Exec{}.Run(ctx, "sh", "-c", "sleep 30 & wait")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > waitDelay/2 {
+		t.Errorf("Run returned after %v; the child process was left running", elapsed)
+	}
+}
+
+func TestExecRunHasNoStdin(t *testing.T) {
+	// A prompt must see end of input instead of waiting for an answer
+	result, err := Exec{}.Run(context.Background(), "sh", "-c", "read answer || echo eof")
+	if err != nil || result.Stdout != "eof\n" {
+		t.Errorf("Run = %+v, %v; want the prompt to see end of input", result, err)
+	}
+}
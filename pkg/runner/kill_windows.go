@@ -0,0 +1,18 @@
+package runner
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// killProcessTree makes cancelling cmd end every process it started.
+// flutter.bat starts dart.exe, which taskkill /T ends along with it
+func killProcessTree(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		kill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid))
+		if err := kill.Run(); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+}
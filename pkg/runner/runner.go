@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// waitDelay bounds how long Run waits for output after a cancelled
+// command was killed, in case a grandchild still holds its pipes
+const waitDelay = 3 * time.Second
+
+// Result holds the captured output of a finished command
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Combined returns stdout followed by stderr
+func (r Result) Combined() string {
+	return r.Stdout + r.Stderr
+}
+
+// CommandRunner runs external commands, so detection logic can be
+// exercised without the real tools installed
+type CommandRunner interface {
+	// Run runs the command and waits for it to finish. The error is
+	// non-nil when the command could not be started, exited non-zero or
+	// was killed because ctx was done. The command gets no stdin, so a
+	// prompt sees end of input instead of blocking
+	Run(ctx context.Context, name string, args ...string) (Result, error)
+	// RunAttached runs the command connected to the terminal, for
+	// commands that prompt the user
+	RunAttached(ctx context.Context, name string, args ...string) error
+	// LookPath searches for an executable in the directories named by PATH
+	LookPath(file string) (string, error)
+}
+
+// Exec runs commands on the host with os/exec
+type Exec struct{}
+
+var _ CommandRunner = Exec{}
+
+// Run runs the command with os/exec. When ctx is done the command is
+// killed together with the processes it started
+func (Exec) Run(ctx context.Context, name string, args ...string) (Result, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	killProcessTree(cmd)
+	err := cmd.Run()
+
+	result := Result{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		result.ExitCode = -1
+	}
+
+	return result, contextError(ctx, err, name, args)
+}
+
+// RunAttached runs the command with the process's stdin, stdout and
+// stderr. It stays in the terminal's process group so that it can read
+// from it, and is killed when ctx is done
+func (Exec) RunAttached(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = waitDelay
+	return contextError(ctx, cmd.Run(), name, args)
+}
+
+// LookPath calls exec.LookPath
+func (Exec) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// contextError replaces the "signal: killed" error of a command stopped
+// by ctx with one that says why it was stopped
+func contextError(ctx context.Context, err error, name string, args []string) error {
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", CommandLine(name, args...), ctx.Err())
+	}
+	return err
+}
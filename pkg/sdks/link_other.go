@@ -0,0 +1,29 @@
+//go:build !windows
+
+package sdks
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// replaceLink points link at target with a relative symlink, so the
+// store can be moved. The new link is renamed over the old one, so the
+// SDK on PATH never disappears
+func replaceLink(target, link string) error {
+	rel, err := filepath.Rel(filepath.Dir(link), target)
+	if err != nil {
+		rel = target
+	}
+
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(rel, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package sdks
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+
+	"golang.org/x/sys/windows"
+)
+
+// replaceLink points link at target with a directory junction. Unlike a
+// symlink it needs neither administrator rights nor developer mode.
+// Removing a junction leaves the SDK it points at alone
+func replaceLink(target, link string) error {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Mkdir(link, 0o755); err != nil {
+		return err
+	}
+	if err := setMountPoint(link, target); err != nil {
+		os.Remove(link)
+		return err
+	}
+	return nil
+}
+
+// setMountPoint turns the empty directory dir into a junction to target
+// by writing a mount point reparse buffer:
+//
+//	ReparseTag, ReparseDataLength, Reserved,
+//	SubstituteNameOffset, SubstituteNameLength,
+//	PrintNameOffset, PrintNameLength,
+//	\??\target NUL target NUL
+func setMountPoint(dir, target string) error {
+	substitute := utf16.Encode([]rune(`\??\` + target))
+	printName := utf16.Encode([]rune(target))
+	names := append(append(append(substitute, 0), printName...), 0)
+
+	buf := make([]byte, 16+2*len(names))
+	binary.LittleEndian.PutUint32(buf[0:], windows.IO_REPARSE_TAG_MOUNT_POINT)
+	binary.LittleEndian.PutUint16(buf[4:], uint16(8+2*len(names)))
+	binary.LittleEndian.PutUint16(buf[8:], 0)
+	binary.LittleEndian.PutUint16(buf[10:], uint16(2*len(substitute)))
+	binary.LittleEndian.PutUint16(buf[12:], uint16(2*(len(substitute)+1)))
+	binary.LittleEndian.PutUint16(buf[14:], uint16(2*len(printName)))
+	for i, c := range names {
+		binary.LittleEndian.PutUint16(buf[16+2*i:], c)
+	}
+
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return err
+	}
+	handle, err := windows.CreateFile(path, windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING,
+		windows.FILE_FLAG_OPEN_REPARSE_POINT|windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(handle)
+
+	var returned uint32
+	return windows.DeviceIoControl(handle, windows.FSCTL_SET_REPARSE_POINT, &buf[0], uint32(len(buf)), nil, 0, &returned, nil)
+}
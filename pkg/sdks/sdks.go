@@ -0,0 +1,178 @@
+package sdks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"flutter_takeoff/pkg/appdirs"
+	"flutter_takeoff/pkg/releases"
+)
+
+// Store keeps several Flutter SDKs side by side under Root:
+//
+//	<Root>/versions/3.24.5
+//	<Root>/versions/3.22.3
+//	<Root>/current -> versions/3.24.5
+//
+// PATH points at <Root>/current/bin, so moving the link switches the SDK
+// every new terminal uses. current is a symlink, or a directory junction
+// on Windows, which needs no administrator rights
+type Store struct {
+	Root string
+}
+
+// SDK is one installed version
+type SDK struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Current bool   `json:"current"`
+	// Size is the disk space the SDK takes, in bytes
+	Size int64 `json:"size"`
+}
+
+// NewStore returns the store kept in the tool's data directory
+func NewStore() (*Store, error) {
+	dir, err := appdirs.DataDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate data directory: %w", err)
+	}
+	return &Store{Root: filepath.Join(dir, "sdks")}, nil
+}
+
+// validVersion matches the version folders the store creates, e.g.
+// "3.24.5" or "v1.12.13+hotfix.9"
+var validVersion = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z.+_-]*$`)
+
+// Path returns where version is installed
+func (s *Store) Path(version string) string {
+	return filepath.Join(s.Root, "versions", version)
+}
+
+// CurrentPath returns the link to the current SDK, the folder whose bin
+// belongs on PATH
+func (s *Store) CurrentPath() string {
+	return filepath.Join(s.Root, "current")
+}
+
+// Installed reports whether version holds a Flutter SDK
+func (s *Store) Installed(version string) bool {
+	if !validVersion.MatchString(version) {
+		return false
+	}
+	for _, name := range []string{"flutter", "flutter.bat"} {
+		if _, err := os.Stat(filepath.Join(s.Path(version), "bin", name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the installed SDKs with their sizes, newest first.
+// Folders without a Flutter SDK, such as an interrupted extraction, are
+// left out
+func (s *Store) List() ([]SDK, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Root, "versions"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.Root, err)
+	}
+	current, err := s.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []SDK
+	for _, e := range entries {
+		if !e.IsDir() || !s.Installed(e.Name()) {
+			continue
+		}
+		sdk := SDK{Version: e.Name(), Path: s.Path(e.Name()), Current: e.Name() == current}
+		if sdk.Size, err = DirSize(sdk.Path); err != nil {
+			return nil, err
+		}
+		out = append(out, sdk)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return releases.CompareVersions(out[i].Version, out[j].Version) > 0
+	})
+	return out, nil
+}
+
+// Current returns the version the current link points at, or "" when
+// there is no link yet
+func (s *Store) Current() (string, error) {
+	target, err := os.Readlink(s.CurrentPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s is not a link to an SDK: %w", s.CurrentPath(), err)
+	}
+	return filepath.Base(filepath.Clean(target)), nil
+}
+
+// Use points the current link at version
+func (s *Store) Use(version string) error {
+	if !s.Installed(version) {
+		return fmt.Errorf("Flutter %s is not installed in %s", version, s.Root)
+	}
+	if err := replaceLink(s.Path(version), s.CurrentPath()); err != nil {
+		return fmt.Errorf("failed to make %s the current SDK: %w", version, err)
+	}
+	return nil
+}
+
+// Remove deletes an SDK that is not the current one and returns the disk
+// space that freed
+func (s *Store) Remove(version string) (int64, error) {
+	if !s.Installed(version) {
+		return 0, fmt.Errorf("Flutter %s is not installed in %s", version, s.Root)
+	}
+	current, err := s.Current()
+	if err != nil {
+		return 0, err
+	}
+	if version == current {
+		return 0, fmt.Errorf("Flutter %s is the current SDK; switch to another version before removing it", version)
+	}
+
+	size, err := DirSize(s.Path(version))
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(s.Path(version)); err != nil {
+		return 0, fmt.Errorf("failed to remove Flutter %s: %w", version, err)
+	}
+	return size, nil
+}
+
+// DirSize adds up the sizes of the files under path, without following
+// links
+func DirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure %s: %w", path, err)
+	}
+	return size, nil
+}
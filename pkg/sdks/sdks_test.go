@@ -0,0 +1,118 @@
+package sdks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// installTestSDK creates a minimal SDK for version in the store
+func installTestSDK(t *testing.T, s *Store, version string, size int) {
+	t.Helper()
+
+	bin := filepath.Join(s.Path(version), "bin")
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "flutter"), make([]byte, size), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreUse(t *testing.T) {
+	s := &Store{Root: t.TempDir()}
+	installTestSDK(t, s, "3.22.3", 10)
+	installTestSDK(t, s, "3.24.5", 20)
+
+	if current, err := s.Current(); err != nil || current != "" {
+		t.Fatalf("Current() before Use = %q, %v", current, err)
+	}
+
+	for _, version := range []string{"3.22.3", "3.24.5"} {
+		if err := s.Use(version); err != nil {
+			t.Fatalf("Use(%s) error = %v", version, err)
+		}
+		if current, err := s.Current(); err != nil || current != version {
+			t.Errorf("Current() = %q, %v; want %s", current, err, version)
+		}
+	}
+
+	// The link leads to the SDK's files
+	if _, err := os.Stat(filepath.Join(s.CurrentPath(), "bin", "flutter")); err != nil {
+		t.Errorf("current/bin/flutter: %v", err)
+	}
+	if _, err := os.Lstat(s.CurrentPath() + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary link left behind: %v", err)
+	}
+
+	if err := s.Use("3.19.6"); err == nil {
+		t.Error("Use() accepted a version that is not installed")
+	}
+	if err := s.Use("../versions/3.24.5"); err == nil {
+		t.Error("Use() accepted a path")
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	s := &Store{Root: t.TempDir()}
+	if list, err := s.List(); err != nil || len(list) != 0 {
+		t.Fatalf("List() of an empty store = %v, %v", list, err)
+	}
+
+	installTestSDK(t, s, "3.22.3", 10)
+	installTestSDK(t, s, "3.24.5", 20)
+	installTestSDK(t, s, "3.27.0-0.1.pre", 30)
+	// An interrupted extraction is not an SDK
+	if err := os.MkdirAll(filepath.Join(s.Root, "versions", ".3.19.6-extract-123"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Use("3.24.5"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("List() = %+v, want 3 SDKs", list)
+	}
+	want := []SDK{
+		{Version: "3.27.0-0.1.pre", Path: s.Path("3.27.0-0.1.pre"), Size: 30},
+		{Version: "3.24.5", Path: s.Path("3.24.5"), Current: true, Size: 20},
+		{Version: "3.22.3", Path: s.Path("3.22.3"), Size: 10},
+	}
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("List()[%d] = %+v, want %+v", i, list[i], want[i])
+		}
+	}
+}
+
+func TestStoreRemove(t *testing.T) {
+	s := &Store{Root: t.TempDir()}
+	installTestSDK(t, s, "3.22.3", 1000)
+	installTestSDK(t, s, "3.24.5", 20)
+	if err := s.Use("3.24.5"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Remove("3.24.5"); err == nil || !strings.Contains(err.Error(), "current SDK") {
+		t.Errorf("Remove(current) error = %v, want current SDK", err)
+	}
+
+	freed, err := s.Remove("3.22.3")
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if freed != 1000 {
+		t.Errorf("Remove() freed %d bytes, want 1000", freed)
+	}
+	if s.Installed("3.22.3") {
+		t.Error("3.22.3 still installed after Remove()")
+	}
+	if !s.Installed("3.24.5") {
+		t.Error("Remove() removed the current SDK")
+	}
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checklistItemMsg marks one checklist entry as finished
+type checklistItemMsg struct {
+	index  int
+	status string
+	detail string
+}
+
+type checklistDoneMsg struct{ err error }
+
+type checklistItem struct {
+	name   string
+	done   bool
+	status string
+	detail string
+}
+
+// ChecklistModel shows a spinner next to each entry until its result
+// arrives, then the StatusIndicator for that result. Entries keep their
+// order however the results arrive. Ctrl+C cancels the work
+type ChecklistModel struct {
+	spinner    spinner.Model
+	title      string
+	items      []checklistItem
+	cancel     context.CancelFunc
+	cancelling bool
+	done       bool
+	err        error
+}
+
+// RunChecklist shows names as a live checklist while work runs. work
+// calls finish with an entry's index, a StatusIndicator status
+// ("success", "error", "warning") and a detail such as a version, from
+// any goroutine, as soon as that entry is known. The checklist stays on
+// screen once work returns
+func RunChecklist(title string, names []string, work func(ctx context.Context, finish func(i int, status, detail string)) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(PrimaryColor)
+
+	items := make([]checklistItem, len(names))
+	for i, name := range names {
+		items[i] = checklistItem{name: name}
+	}
+
+	p := tea.NewProgram(ChecklistModel{spinner: s, title: title, items: items, cancel: cancel})
+	go func() {
+		err := work(ctx, func(i int, status, detail string) {
+			p.Send(checklistItemMsg{index: i, status: status, detail: detail})
+		})
+		p.Send(checklistDoneMsg{err: err})
+	}()
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+	return finalModel.(ChecklistModel).err
+}
+
+func (m ChecklistModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m ChecklistModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case checklistItemMsg:
+		if msg.index >= 0 && msg.index < len(m.items) {
+			item := &m.items[msg.index]
+			item.done, item.status, item.detail = true, msg.status, msg.detail
+		}
+		return m, nil
+
+	case checklistDoneMsg:
+		m.done = true
+		m.err = msg.err
+		return m, tea.Quit
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" && !m.cancelling {
+			m.cancelling = true
+			m.cancel()
+		}
+	}
+
+	return m, nil
+}
+
+func (m ChecklistModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(HeaderStyle.Render(m.title) + "\n\n")
+	for _, item := range m.items {
+		switch {
+		case item.done:
+			line := StatusIndicator(item.status, item.name+":")
+			if item.detail != "" {
+				line += " " + SubtleStyle.Render(item.detail)
+			}
+			b.WriteString(line + "\n")
+		case m.done:
+			// Work ended without a result for this entry, e.g. cancelled
+			b.WriteString(StatusIndicator("", item.name+":") + " " + SubtleStyle.Render("not checked") + "\n")
+		default:
+			b.WriteString(m.spinner.View() + item.name + "\n")
+		}
+	}
+
+	if !m.done {
+		if m.cancelling {
+			b.WriteString("\n" + WarningStyle.Render("Cancelling...") + "\n")
+		} else {
+			b.WriteString("\n" + HelpStyle.Render("ctrl+c to cancel") + "\n")
+		}
+	}
+	return b.String()
+}
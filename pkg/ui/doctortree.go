@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"strings"
+
+	"flutter_takeoff/pkg/doctor"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DoctorTreeModel shows a parsed flutter doctor report as a tree whose
+// categories can be expanded to reveal their details and hints
+type DoctorTreeModel struct {
+	report   *doctor.Report
+	cursor   int
+	expanded map[int]bool
+	height   int
+	quitting bool
+}
+
+// NewDoctorTree creates the tree with every category that needs
+// attention already expanded
+func NewDoctorTree(report *doctor.Report) DoctorTreeModel {
+	expanded := make(map[int]bool)
+	for i, c := range report.Categories {
+		if c.Status != doctor.StatusOK {
+			expanded[i] = true
+		}
+	}
+	return DoctorTreeModel{report: report, expanded: expanded}
+}
+
+func (m DoctorTreeModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m DoctorTreeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.report.Categories)-1 {
+				m.cursor++
+			}
+		case "enter", " ":
+			m.expanded[m.cursor] = !m.expanded[m.cursor]
+		case "right", "l":
+			m.expanded[m.cursor] = true
+		case "left", "h":
+			m.expanded[m.cursor] = false
+		case "a":
+			// Expand everything, or collapse everything if already expanded.
+			// Collapsed categories may be in the map as false
+			open := 0
+			for _, expanded := range m.expanded {
+				if expanded {
+					open++
+				}
+			}
+			all := open == len(m.report.Categories)
+			for i := range m.report.Categories {
+				m.expanded[i] = !all
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m DoctorTreeModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var lines []string
+	cursorLine := 0
+	for i, c := range m.report.Categories {
+		if i == m.cursor {
+			cursorLine = len(lines)
+		}
+		lines = append(lines, m.renderCategory(i, c))
+		if m.expanded[i] {
+			for _, msg := range c.Messages {
+				lines = append(lines, renderDoctorMessage(msg)...)
+			}
+		}
+	}
+
+	// Keep the selected category on screen when the tree is taller than the terminal
+	if visible := m.height - 4; visible > 0 && len(lines) > visible {
+		start := cursorLine - visible/2
+		if start < 0 {
+			start = 0
+		}
+		if start > len(lines)-visible {
+			start = len(lines) - visible
+		}
+		lines = lines[start : start+visible]
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n")
+
+	if m.report.Summary != "" {
+		b.WriteString("\n" + NormalStyle.Render(m.report.Summary) + "\n")
+	}
+	b.WriteString(HelpStyle.Render("↑/↓ move • enter toggle • a expand all • q back"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (m DoctorTreeModel) renderCategory(i int, c doctor.Category) string {
+	arrow := "▸"
+	if m.expanded[i] {
+		arrow = "▾"
+	}
+	if len(c.Messages) == 0 {
+		arrow = " "
+	}
+
+	title := c.Name
+	if c.Summary != "" {
+		title += SubtleStyle.Render(" (" + c.Summary + ")")
+	}
+
+	line := DoctorStatus(c.Status) + " " + title
+	if i == m.cursor {
+		return SelectedItemStyle.Render(arrow) + " " + line
+	}
+	return UnselectedItemStyle.Render(arrow) + " " + line
+}
+
+func renderDoctorMessage(msg doctor.Message) []string {
+	var icon string
+	var style lipgloss.Style
+	switch msg.Type {
+	case doctor.MessageError:
+		icon, style = "✗", ErrorStyle
+	case doctor.MessageHint:
+		icon, style = "!", WarningStyle
+	default:
+		icon, style = "•", SubtleStyle
+	}
+
+	var out []string
+	for j, text := range strings.Split(msg.Text, "\n") {
+		prefix := "        " + style.Render(icon) + " "
+		if j > 0 {
+			prefix = "          "
+		}
+		out = append(out, prefix+SubtleStyle.Render(text))
+	}
+	return out
+}
+
+// DoctorStatus renders a category status the way flutter doctor prints it
+func DoctorStatus(s doctor.Status) string {
+	text := "[" + s.Symbol() + "]"
+	switch s {
+	case doctor.StatusOK:
+		return SuccessStyle.Render(text)
+	case doctor.StatusPartial:
+		return WarningStyle.Render(text)
+	default:
+		return ErrorStyle.Render(text)
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PagerModel shows a long text, such as a licence, in a scrollable
+// view and asks the user to accept or decline it
+type PagerModel struct {
+	title    string
+	content  string
+	viewport viewport.Model
+	ready    bool
+	accepted bool
+	done     bool
+}
+
+// NewPager creates a pager for content. The text is wrapped to the
+// terminal width once it is known
+func NewPager(title, content string) PagerModel {
+	return PagerModel{title: title, content: content}
+}
+
+func (m PagerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m PagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the title above and the help line below
+		height := msg.Height - 5
+		if height < 1 {
+			height = 1
+		}
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.ready = true
+		} else {
+			m.viewport.Width, m.viewport.Height = msg.Width, height
+		}
+		m.viewport.SetContent(lipgloss.NewStyle().Width(msg.Width).Render(m.content))
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc", "n":
+			m.done = true
+			return m, tea.Quit
+		case "a", "y":
+			m.accepted, m.done = true, true
+			return m, tea.Quit
+		case "home", "g":
+			m.viewport.GotoTop()
+			return m, nil
+		case "end", "G":
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m PagerModel) View() string {
+	if m.done || !m.ready {
+		return ""
+	}
+
+	title := TitleStyle.Render(m.title)
+	help := HelpStyle.Render(fmt.Sprintf("↑/↓ scroll • pgup/pgdn page • a accept • q decline  %3.0f%%", m.viewport.ScrollPercent()*100))
+	return title + "\n" + m.viewport.View() + "\n" + help
+}
+
+// Accepted reports whether the user accepted the text
+func (m PagerModel) Accepted() bool {
+	return m.accepted
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"context"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type taskDoneMsg struct{ err error }
+
+// TaskModel shows a spinner while a task runs in the background. Ctrl+C
+// cancels the task's context and waits for it to stop, so commands it
+// started are killed rather than left running
+type TaskModel struct {
+	spinner    spinner.Model
+	title      string
+	cancel     context.CancelFunc
+	cancelling bool
+	done       bool
+	err        error
+}
+
+// RunTask runs task while showing title next to a spinner, and returns
+// the task's error. A task cancelled with Ctrl+C gets a cancelled context
+func RunTask(title string, task func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(PrimaryColor)
+
+	p := tea.NewProgram(TaskModel{spinner: s, title: title, cancel: cancel})
+	go func() {
+		p.Send(taskDoneMsg{err: task(ctx)})
+	}()
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+	return finalModel.(TaskModel).err
+}
+
+func (m TaskModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m TaskModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case taskDoneMsg:
+		m.done = true
+		m.err = msg.err
+		return m, tea.Quit
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" && !m.cancelling {
+			m.cancelling = true
+			m.cancel()
+		}
+	}
+
+	return m, nil
+}
+
+func (m TaskModel) View() string {
+	if m.done {
+		return ""
+	}
+	if m.cancelling {
+		return "\n " + m.spinner.View() + " " + WarningStyle.Render("Cancelling...") + "\n"
+	}
+	return "\n " + m.spinner.View() + " " + m.title + " " + HelpStyle.Render("(ctrl+c to cancel)") + "\n"
+}